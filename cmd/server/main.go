@@ -1,57 +1,201 @@
 package main
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
 	"posts-api/internal/config"
 	"posts-api/internal/handlers"
-	"posts-api/internal/models"
+	"posts-api/internal/logging"
+	"posts-api/internal/metrics"
 	"posts-api/internal/repository"
 	"posts-api/internal/routes"
 	"posts-api/internal/services"
+	"posts-api/internal/tracing"
+	"posts-api/pkg/utils"
+	"sync"
+	"syscall"
+	"time"
 )
 func main() {
-    fmt.Println("Server starting...")
 	appConfig, err := config.LoadConfig()
 	if err != nil {
-		log.Fatal("Error loading configuration:", err)
+		fatal("Error loading configuration", "error", err)
 	}
-	fmt.Println("Initializing database connection...")
+	if err := logging.Setup(appConfig.Log.Level, appConfig.Log.Format); err != nil {
+		fatal("Error configuring logging", "error", err)
+	}
+	utils.SetVerboseErrors(appConfig.Development)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    appConfig.Tracing.Exporter,
+		ServiceName: appConfig.Tracing.ServiceName,
+	})
+	if err != nil {
+		fatal("Error configuring tracing", "error", err)
+	}
+	slog.Info("Initializing database connection")
 	config.InitDatabase(appConfig.Database)
 	defer config.CloseDatabase()
-	fmt.Println("Database connection established successfully\nMigrating database...")
-	err = config.DB.AutoMigrate(&models.Post{})
-	if err != nil {
-		log.Fatalf("Error migrating database: %v", err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), os.Args[2:]); err != nil {
+			config.CloseDatabase()
+			fatal("Migration command failed", "error", err)
+		}
+		return
+	}
+	if appConfig.Database.MigrateOnStart {
+		slog.Info("Migrating database")
+		migrator, err := newMigrator()
+		if err != nil {
+			fatal("Error loading migrations", "error", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			fatal("Error migrating database", "error", err)
+		}
+		slog.Info("Database migration completed successfully", "applied", applied)
 	}
-	fmt.Println("Database migration completed successfully")
-	fmt.Println("Initializing services...")
 	
 	postRepo := repository.NewPostRepository(config.DB)
 	usersAPIURL := appConfig.Server.UsersAPIURL
 	if usersAPIURL == "" {
-		log.Fatal("USERS_API_URL environment variable is required")
+		fatal("USERS_API_URL environment variable is required")
+	}
+	var userService services.UserService = services.NewUserService(usersAPIURL, services.UserServiceConfig{
+		Timeout:          appConfig.UsersAPI.Timeout,
+		MaxRetries:       appConfig.UsersAPI.MaxRetries,
+		RetryBackoff:     appConfig.UsersAPI.RetryBackoff,
+		BreakerThreshold: appConfig.UsersAPI.BreakerThreshold,
+		BreakerCooldown:  appConfig.UsersAPI.BreakerCooldown,
+	})
+	if appConfig.Auth.Mode == config.AuthModeJWT {
+		userService, err = services.NewJWTUserService(services.JWTConfig{
+			JWKSURL:         appConfig.Auth.JWKSURL,
+			HMACSecret:      appConfig.Auth.JWTHMACSecret,
+			Issuer:          appConfig.Auth.JWTIssuer,
+			Audience:        appConfig.Auth.JWTAudience,
+			RefreshInterval: appConfig.Auth.JWKSRefreshInterval,
+			Leeway:          30 * time.Second,
+		})
+		if err != nil {
+			fatal("Error configuring JWT verification", "error", err)
+		}
+	} else if appConfig.Auth.TokenCacheTTL > 0 {
+		userService = services.NewCachingUserService(userService, services.TokenCacheConfig{
+			TTL:         appConfig.Auth.TokenCacheTTL,
+			NegativeTTL: appConfig.Auth.TokenCacheNegativeTTL,
+			MaxEntries:  appConfig.Auth.TokenCacheMaxEntries,
+		})
 	}
-	userService := services.NewUserService(usersAPIURL)
 	
-	postService := services.NewPostService(postRepo, userService)
-	postHandler := handlers.NewPostHandler(postService)
+	authorizer := services.NewAuthorizer()
+	postService := services.NewTracingPostService(services.NewPostService(postRepo, userService, authorizer))
+	postHandler := handlers.NewPostHandler(postService, appConfig.Server.RequireIfMatch)
+	revisionService := services.NewRevisionService(postRepo, repository.NewPostRevisionRepository(config.DB), authorizer)
+	revisionHandler := handlers.NewRevisionHandler(revisionService, appConfig.Server.RequireIfMatch)
+	tagHandler := handlers.NewTagHandler(services.NewTagService(repository.NewTagRepository(config.DB)))
+	commentService := services.NewCommentService(postRepo, repository.NewCommentRepository(config.DB), authorizer)
+	commentHandler := handlers.NewCommentHandler(commentService)
 	
-	handler := routes.SetupRoutes(postHandler, userService)
+	if cache, ok := userService.(services.CachingUserService); ok {
+		metrics.RegisterTokenCache(func() metrics.TokenCacheStats {
+			stats := cache.CacheStats()
+			return metrics.TokenCacheStats{
+				Hits:         stats.Hits,
+				NegativeHits: stats.NegativeHits,
+				Misses:       stats.Misses,
+				Evictions:    stats.Evictions,
+				Size:         stats.Size,
+			}
+		})
+	}
+	healthService := services.NewHealthService(appConfig.Server.HealthCheckTimeout,
+		services.DependencyCheck{Name: "database", Required: true, Check: config.PingDatabase},
+		services.DependencyCheck{
+			Name: "users_api",
+			// Public reads keep working while the Users API is down, so an
+			// outage degrades the service rather than taking it out of rotation.
+			Required: false,
+			Check:    services.NewUsersAPIHealthCheck(usersAPIURL, appConfig.UsersAPI.HealthPath),
+		},
+	)
+	healthHandler := handlers.NewHealthHandler(healthService, userService)
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	var jobs sync.WaitGroup
+	if appConfig.Trash.Retention > 0 {
+		purger := services.NewTrashPurger(postRepo, services.TrashPurgerConfig{
+			Retention: appConfig.Trash.Retention,
+			Interval:  appConfig.Trash.PurgeInterval,
+			BatchSize: appConfig.Trash.PurgeBatchSize,
+		})
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			purger.Run(jobsCtx)
+		}()
+	}
+	if appConfig.Scheduler.Enabled {
+		scheduler := services.NewPostScheduler(postRepo, services.PostSchedulerConfig{
+			Interval:  appConfig.Scheduler.Interval,
+			BatchSize: appConfig.Scheduler.BatchSize,
+		})
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			scheduler.Run(jobsCtx)
+		}()
+	}
+	
+	handler := routes.SetupRoutes(postHandler, revisionHandler, tagHandler, commentHandler, healthHandler, userService)
 	port := ":8080"
 	if portEnv := appConfig.Server.Port; portEnv != "" {
 		port = ":" + portEnv
 	}
-	fmt.Printf("Server starting on port %s\n", port)
-	fmt.Println("Visit http://localhost" + port + " to test the API")
-	fmt.Println("API endpoints:")
-	fmt.Println("  GET    / - Root endpoint")
-	fmt.Println("  GET    /health - Health check")
-	fmt.Println("  GET    /api/v1/posts - Get all posts")
-	fmt.Println("  GET    /api/v1/posts/{id} - Get single post")
-	fmt.Println("  GET    /api/v1/posts/author/{authorId} - Get posts by author")
-	fmt.Println("  POST   /api/v1/posts - Create post (auth required)")
-	fmt.Println("  PUT    /api/v1/posts/{id} - Update post (auth required)")
-	fmt.Println("  DELETE /api/v1/posts/{id} - Delete post (auth required)")
-    log.Fatal(http.ListenAndServe(port, handler))
+	slog.Info("Server starting", "addr", port, "auth_mode", appConfig.Auth.Mode)
+	srv := &http.Server{
+		Addr:              port,
+		Handler:           handler,
+		ReadTimeout:       appConfig.Server.ReadTimeout,
+		ReadHeaderTimeout: appConfig.Server.ReadHeaderTimeout,
+		WriteTimeout:      appConfig.Server.WriteTimeout,
+		IdleTimeout:       appConfig.Server.IdleTimeout,
+		MaxHeaderBytes:    appConfig.Server.MaxHeaderBytes,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			config.CloseDatabase()
+			fatal("Server error", "error", err)
+		}
+		return
+	case <-ctx.Done():
+	}
+	stop()
+	slog.Info("Shutdown signal received, draining connections")
+	healthHandler.SetReady(false)
+	time.Sleep(appConfig.Server.ShutdownDrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), appConfig.Server.ShutdownGracePeriod)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Graceful shutdown did not complete", "error", err)
+	}
+	stopJobs()
+	jobs.Wait()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -1,10 +1,17 @@
 package config
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"posts-api/internal/logging"
+	"posts-api/internal/metrics"
+	"posts-api/internal/tracing"
+	"time"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 var DB *gorm.DB
 func InitDatabase(cfg DatabaseConfig) {
@@ -12,26 +19,51 @@ func InitDatabase(cfg DatabaseConfig) {
 		cfg.Host, cfg.Username, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode)
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logging.NewGormLogger(200 * time.Millisecond),
 	})
 	if err != nil {
-		log.Fatal("Failed to connect to the database:", err)
+		slog.Error("Failed to connect to the database", "error", err)
+		os.Exit(1)
 	}
-	log.Println("Database connection established successfully")	
+	if err := metrics.InstrumentGORM(DB); err != nil {
+		slog.Error("Failed to register database metrics", "error", err)
+		os.Exit(1)
+	}
+	if err := tracing.InstrumentGORM(DB); err != nil {
+		slog.Error("Failed to register database tracing", "error", err)
+		os.Exit(1)
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		slog.Error("Failed to get database instance", "error", err)
+		os.Exit(1)
+	}
+	metrics.MustRegister(collectors.NewDBStatsCollector(sqlDB, cfg.DBName))
+	slog.Info("Database connection established successfully")
 }
 func CloseDatabase() {
 	if DB != nil {
 		sqlDB, err := DB.DB()
 		if err != nil {
-			log.Println("Error getting database instance:", err)
+			slog.Error("Error getting database instance", "error", err)
 			return
 		}
 		err = sqlDB.Close()
 		if err != nil {
-			log.Println("Error closing database connection:", err)
+			slog.Error("Error closing database connection", "error", err)
 			return
 		} else {
-			log.Println("Database connection closed successfully")
+			slog.Info("Database connection closed successfully")
 		}
 	}
 }
+func PingDatabase(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
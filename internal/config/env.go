@@ -1,8 +1,10 @@
 package config
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"time"
 	"github.com/joho/godotenv"
 )
 type DatabaseConfig struct {
@@ -12,18 +14,82 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// MigrateOnStart applies pending migrations before the server starts.
+	MigrateOnStart bool
 }
 type ServerConfig struct {
-	Port        string
-	UsersAPIURL string
+	Port              string
+	UsersAPIURL       string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownDrainDelay is how long readiness reports failure before the
+	// server stops accepting connections.
+	ShutdownDrainDelay  time.Duration
+	ShutdownGracePeriod time.Duration
+	HealthCheckTimeout  time.Duration
+	// RequireIfMatch makes If-Match mandatory on post updates and deletes.
+	RequireIfMatch bool
+}
+const (
+	AuthModeRemote = "remote"
+	AuthModeJWT    = "jwt"
+)
+type AuthConfig struct {
+	Mode                  string
+	JWKSURL               string
+	JWTHMACSecret         string
+	JWTIssuer             string
+	JWTAudience           string
+	JWKSRefreshInterval   time.Duration
+	TokenCacheTTL         time.Duration
+	TokenCacheNegativeTTL time.Duration
+	TokenCacheMaxEntries  int
+}
+type UsersAPIConfig struct {
+	HealthPath       string
+	Timeout          time.Duration
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+type LogConfig struct {
+	Level  string
+	Format string
+}
+type TrashConfig struct {
+	// Retention is how long deleted posts can be restored; 0 disables purging.
+	Retention      time.Duration
+	PurgeInterval  time.Duration
+	PurgeBatchSize int
+}
+type SchedulerConfig struct {
+	Enabled   bool
+	Interval  time.Duration
+	BatchSize int
+}
+type TracingConfig struct {
+	Exporter    string
+	ServiceName string
 }
 type AppConfig struct {
+	// Development re-enables internal error details in API responses.
+	Development bool
+	Log         LogConfig
+	Tracing     TracingConfig
 	Database DatabaseConfig
 	Server ServerConfig
+	Auth     AuthConfig
+	UsersAPI UsersAPIConfig
+	Trash    TrashConfig
+	Scheduler SchedulerConfig
 }
 func LoadConfig() (*AppConfig, error) {
 	if err := godotenv.Load(); err != nil {
-		log.Printf("Error loading .env file: %v", err)
+		slog.Warn("Could not load .env file", "error", err)
 	}
 	cfg := &AppConfig{
 		Database: DatabaseConfig{
@@ -38,6 +104,101 @@ func LoadConfig() (*AppConfig, error) {
 			UsersAPIURL: os.Getenv("USERS_API_URL"),
 		},
 	}
+	cfg.Log.Level = getEnv("LOG_LEVEL", "info")
+	cfg.Log.Format = getEnv("LOG_FORMAT", "json")
+	cfg.Tracing.Exporter = getEnv("TRACING_EXPORTER", "none")
+	cfg.Tracing.ServiceName = getEnv("OTEL_SERVICE_NAME", "posts-api")
+	cfg.Auth.Mode = getEnv("AUTH_MODE", AuthModeRemote)
+	if cfg.Auth.Mode != AuthModeRemote && cfg.Auth.Mode != AuthModeJWT {
+		return nil, fmt.Errorf("AUTH_MODE must be %q or %q", AuthModeRemote, AuthModeJWT)
+	}
+	cfg.Auth.JWKSURL = os.Getenv("JWT_JWKS_URL")
+	if cfg.Auth.JWKSURL == "" && cfg.Server.UsersAPIURL != "" {
+		cfg.Auth.JWKSURL = cfg.Server.UsersAPIURL + "/.well-known/jwks.json"
+	}
+	cfg.Auth.JWTHMACSecret = os.Getenv("JWT_HMAC_SECRET")
+	cfg.Auth.JWTIssuer = os.Getenv("JWT_ISSUER")
+	cfg.Auth.JWTAudience = os.Getenv("JWT_AUDIENCE")
+	var err error
+	if cfg.Development, err = getEnvBool("DEVELOPMENT_MODE", false); err != nil {
+		return nil, err
+	}
+	if cfg.Server.RequireIfMatch, err = getEnvBool("REQUIRE_IF_MATCH", false); err != nil {
+		return nil, err
+	}
+	if cfg.Database.MigrateOnStart, err = getEnvBool("DATABASE_MIGRATE_ON_START", true); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.JWKSRefreshInterval, err = getEnvDuration("JWKS_REFRESH_INTERVAL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.TokenCacheTTL, err = getEnvDuration("TOKEN_CACHE_TTL", 60*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.TokenCacheNegativeTTL, err = getEnvDuration("TOKEN_CACHE_NEGATIVE_TTL", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Auth.TokenCacheMaxEntries, err = getEnvInt("TOKEN_CACHE_MAX_ENTRIES", 10000); err != nil {
+		return nil, err
+	}
+	if cfg.Server.ReadTimeout, err = getEnvDuration("SERVER_READ_TIMEOUT", 15*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Server.ReadHeaderTimeout, err = getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Server.WriteTimeout, err = getEnvDuration("SERVER_WRITE_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Server.IdleTimeout, err = getEnvDuration("SERVER_IDLE_TIMEOUT", 60*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Server.MaxHeaderBytes, err = getEnvInt("SERVER_MAX_HEADER_BYTES", 1<<20); err != nil {
+		return nil, err
+	}
+	if cfg.Server.ShutdownDrainDelay, err = getEnvDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Server.ShutdownGracePeriod, err = getEnvDuration("SHUTDOWN_GRACE_PERIOD", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Server.HealthCheckTimeout, err = getEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Trash.Retention, err = getEnvDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Trash.PurgeInterval, err = getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.Trash.PurgeBatchSize, err = getEnvInt("TRASH_PURGE_BATCH_SIZE", 500); err != nil {
+		return nil, err
+	}
+	if cfg.Scheduler.Enabled, err = getEnvBool("SCHEDULER_ENABLED", true); err != nil {
+		return nil, err
+	}
+	if cfg.Scheduler.Interval, err = getEnvDuration("SCHEDULER_INTERVAL", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.Scheduler.BatchSize, err = getEnvInt("SCHEDULER_BATCH_SIZE", 100); err != nil {
+		return nil, err
+	}
+	cfg.UsersAPI.HealthPath = getEnv("USERS_API_HEALTH_PATH", "/health")
+	if cfg.UsersAPI.Timeout, err = getEnvDuration("USERS_API_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.UsersAPI.MaxRetries, err = getEnvInt("USERS_API_MAX_RETRIES", 2); err != nil {
+		return nil, err
+	}
+	if cfg.UsersAPI.RetryBackoff, err = getEnvDuration("USERS_API_RETRY_BACKOFF", 100*time.Millisecond); err != nil {
+		return nil, err
+	}
+	if cfg.UsersAPI.BreakerThreshold, err = getEnvInt("USERS_API_BREAKER_THRESHOLD", 5); err != nil {
+		return nil, err
+	}
+	if cfg.UsersAPI.BreakerCooldown, err = getEnvDuration("USERS_API_BREAKER_COOLDOWN", 30*time.Second); err != nil {
+		return nil, err
+	}
 	if cfg.Database.Host == "" || cfg.Database.Port == "" || cfg.Database.Username == "" ||
 		cfg.Database.Password == "" || cfg.Database.DBName == "" {
 		return nil, fmt.Errorf("missing required database environment variables")
@@ -50,3 +211,36 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	dur, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration for %s: %w", key, err)
+	}
+	return dur, nil
+}
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	num, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer for %s: %w", key, err)
+	}
+	return num, nil
+}
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean for %s: %w", key, err)
+	}
+	return b, nil
+}
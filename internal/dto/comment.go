@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"posts-api/internal/models"
+	"posts-api/pkg/utils"
+	"time"
+)
+type CreateCommentRequest struct {
+	Content string `json:"content" validate:"required,min=1,max=2000"`
+	// ParentID makes the comment a reply to a top-level comment.
+	ParentID *int64 `json:"parent_id,omitempty" validate:"omitempty,min=1"`
+}
+type UpdateCommentRequest struct {
+	Content string `json:"content" validate:"required,min=1,max=2000"`
+}
+type CommentResponse struct {
+	ID         int64             `json:"id"`
+	PostID     int64             `json:"post_id"`
+	ParentID   *int64            `json:"parent_id,omitempty"`
+	AuthorID   int64             `json:"author_id"`
+	AuthorName string            `json:"author_name"`
+	Content    string            `json:"content"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Replies    []CommentResponse `json:"replies,omitempty"`
+	// ReplyCount is the total number of replies; when it exceeds the
+	// replies shown, RepliesNextCursor continues them on the replies
+	// endpoint.
+	ReplyCount        int64  `json:"reply_count,omitempty"`
+	RepliesNextCursor string `json:"replies_next_cursor,omitempty"`
+}
+type CommentListResponse struct {
+	Comments   []CommentResponse `json:"comments"`
+	PageSize   int               `json:"page_size"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+func NewCommentResponse(comment *models.Comment) *CommentResponse {
+	response := &CommentResponse{
+		ID:         comment.ID,
+		PostID:     comment.PostID,
+		ParentID:   comment.ParentID,
+		AuthorID:   comment.AuthorID,
+		AuthorName: comment.AuthorName,
+		Content:    comment.Content,
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
+		ReplyCount: comment.ReplyCount,
+	}
+	for i := range comment.Replies {
+		response.Replies = append(response.Replies, *NewCommentResponse(&comment.Replies[i]))
+	}
+	if n := len(comment.Replies); n > 0 && comment.ReplyCount > int64(n) {
+		last := comment.Replies[n-1]
+		response.RepliesNextCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID, Direction: utils.CursorNext})
+	}
+	return response
+}
+func NewCommentListResponse(comments []*models.Comment, pageSize int, next *utils.Cursor) *CommentListResponse {
+	responses := make([]CommentResponse, len(comments))
+	for i, comment := range comments {
+		responses[i] = *NewCommentResponse(comment)
+	}
+	response := &CommentListResponse{
+		Comments: responses,
+		PageSize: pageSize,
+	}
+	if next != nil {
+		response.NextCursor = utils.EncodeCursor(*next)
+	}
+	return response
+}
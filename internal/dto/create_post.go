@@ -5,8 +5,14 @@ import (
 	"time"
 )
 type CreatePostRequest struct {
-    Title   string `json:"title" validate:"required,min=1,max=255"`
-    Content string `json:"content" validate:"required,min=1,max=10000"`
+    Title   string   `json:"title" validate:"required,min=1,max=255"`
+    Content string   `json:"content" validate:"required,min=1,max=10000"`
+    Tags    []string `json:"tags,omitempty" validate:"omitempty,max=10,dive,min=1,max=50"`
+    // Status is the initial status and defaults to published. Later changes
+    // go through the publish, unpublish and archive endpoints.
+    Status string `json:"status,omitempty" validate:"omitempty,oneof=draft published"`
+    // PublishAt schedules the post to be published at that time instead.
+    PublishAt *time.Time `json:"publish_at,omitempty"`
 }
 func (req *CreatePostRequest) ToModel(authorID int64) *models.Post {
     return &models.Post{
@@ -18,15 +24,27 @@ func (req *CreatePostRequest) ToModel(authorID int64) *models.Post {
     }
 }
 func (req *CreatePostRequest) ToModelWithAuthor(authorID int64, authorName, authorEmail string) *models.Post {
-    return &models.Post{
+    now := time.Now()
+    post := &models.Post{
         Title:       req.Title,
         Content:     req.Content,
         AuthorID:    authorID,
         AuthorName:  authorName,
         AuthorEmail: authorEmail,
-        CreatedAt:   time.Now(),
-        UpdatedAt:   time.Now(),
+        Status:      models.PostStatusPublished,
+        CreatedAt:   now,
+        UpdatedAt:   now,
+    }
+    switch {
+    case req.PublishAt != nil:
+        post.Status = models.PostStatusScheduled
+        post.PublishAt = req.PublishAt
+    case req.Status == models.PostStatusDraft:
+        post.Status = models.PostStatusDraft
+    default:
+        post.PublishedAt = &now
     }
+    return post
 }
 func (req *CreatePostRequest) Validate() error {
     return nil
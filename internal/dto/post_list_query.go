@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+var PostSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"title":      true,
+}
+type PostListQuery struct {
+	AuthorIDs     []int64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedSince  *time.Time
+	Tags          []string
+	MatchAllTags  bool
+	Sort          string
+	Order         string
+}
+// IsDefaultSort reports whether the query keeps the newest-first ordering
+// that cursor pagination is keyed on.
+func (q *PostListQuery) IsDefaultSort() bool {
+	return (q.Sort == "" || q.Sort == "created_at") && (q.Order == "" || q.Order == SortOrderDesc)
+}
+// SortDesc resolves the effective direction: timestamps default to newest
+// first, title defaults to alphabetical.
+func (q *PostListQuery) SortDesc() bool {
+	if q.Order != "" {
+		return q.Order == SortOrderDesc
+	}
+	return q.Sort != "title"
+}
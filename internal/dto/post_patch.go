@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+// NewPostDocument returns the editable fields of a post as the JSON object
+// PATCH documents are applied to. publish_at is null unless the post is
+// scheduled, so a JSON Patch can replace it to schedule a draft.
+func NewPostDocument(post *PostResponse) map[string]interface{} {
+	// Tags are stored as []interface{} and publish_at as its JSON string so
+	// they compare equal to decoded JSON in patch test operations.
+	tags := make([]interface{}, len(post.Tags))
+	for i, tag := range post.Tags {
+		tags[i] = tag
+	}
+	var publishAt interface{}
+	if post.PublishAt != nil {
+		publishAt = post.PublishAt.Format(time.RFC3339Nano)
+	}
+	return map[string]interface{}{
+		"title":      post.Title,
+		"content":    post.Content,
+		"tags":       tags,
+		"publish_at": publishAt,
+	}
+}
+// ToPatchUpdateRequest is ToUpdateRequest for the result of patching current.
+// A patch that leaves publish_at null or removes it unschedules the post, as
+// null is how the document shows a post that isn't scheduled.
+func (req *CreatePostRequest) ToPatchUpdateRequest(current *PostResponse) *UpdatePostRequest {
+	update := req.ToUpdateRequest()
+	update.Unschedule = current.PublishAt != nil && req.PublishAt == nil
+	return update
+}
+// ToUpdateRequest turns a complete post representation, as sent with PUT or
+// produced by applying a PATCH, into an update that sets every field. Only
+// publish_at is left alone when absent, as it is scheduling state rather
+// than content.
+func (req *CreatePostRequest) ToUpdateRequest() *UpdatePostRequest {
+	title, content := req.Title, req.Content
+	tags := append([]string{}, req.Tags...)
+	return &UpdatePostRequest{
+		Title:     &title,
+		Content:   &content,
+		Tags:      &tags,
+		PublishAt: req.PublishAt,
+	}
+}
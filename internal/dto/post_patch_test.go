@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"posts-api/pkg/utils"
+	"testing"
+	"time"
+)
+// applyToPost applies a patch the way the PATCH handler does and decodes the
+// result back into a full post representation.
+func applyToPost(t *testing.T, post *PostResponse, apply func(map[string]interface{}, []byte) (map[string]interface{}, error), patch string) *CreatePostRequest {
+	t.Helper()
+	patched, err := apply(NewPostDocument(post), []byte(patch))
+	if err != nil {
+		t.Fatalf("failed to apply patch: %v", err)
+	}
+	patchedJSON, err := json.Marshal(patched)
+	if err != nil {
+		t.Fatalf("failed to encode patched document: %v", err)
+	}
+	var req CreatePostRequest
+	decoder := json.NewDecoder(bytes.NewReader(patchedJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		t.Fatalf("failed to decode patched document: %v", err)
+	}
+	return &req
+}
+func TestNewPostDocumentPublishAt(t *testing.T) {
+	publishAt := time.Date(2030, 1, 2, 3, 4, 5, 6000, time.UTC)
+	draft := &PostResponse{Title: "Title", Content: "Content", Tags: []string{"go"}}
+	scheduled := &PostResponse{Title: "Title", Content: "Content", PublishAt: &publishAt}
+	if doc := NewPostDocument(draft); doc["publish_at"] != nil {
+		t.Errorf("draft publish_at = %v, want null", doc["publish_at"])
+	}
+	tests := []struct {
+		name  string
+		post  *PostResponse
+		apply func(map[string]interface{}, []byte) (map[string]interface{}, error)
+		patch string
+		want  *time.Time
+	}{
+		{
+			name:  "json patch schedules a draft",
+			post:  draft,
+			apply: utils.ApplyJSONPatch,
+			patch: `[{"op":"replace","path":"/publish_at","value":"2030-01-02T03:04:05.000006Z"}]`,
+			want:  &publishAt,
+		},
+		{
+			name:  "merge patch schedules a draft",
+			post:  draft,
+			apply: utils.ApplyMergePatch,
+			patch: `{"publish_at":"2030-01-02T03:04:05.000006Z"}`,
+			want:  &publishAt,
+		},
+		{
+			name:  "json patch tests the current schedule",
+			post:  scheduled,
+			apply: utils.ApplyJSONPatch,
+			patch: `[{"op":"test","path":"/publish_at","value":"2030-01-02T03:04:05.000006Z"},{"op":"replace","path":"/title","value":"New"}]`,
+			want:  &publishAt,
+		},
+		{
+			name:  "unrelated edit keeps the schedule",
+			post:  scheduled,
+			apply: utils.ApplyMergePatch,
+			patch: `{"title":"New"}`,
+			want:  &publishAt,
+		},
+		{
+			name:  "unrelated edit leaves a draft unscheduled",
+			post:  draft,
+			apply: utils.ApplyJSONPatch,
+			patch: `[{"op":"replace","path":"/title","value":"New"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := applyToPost(t, tt.post, tt.apply, tt.patch).ToUpdateRequest()
+			switch {
+			case tt.want == nil && update.PublishAt != nil:
+				t.Errorf("publish_at = %v, want unset", update.PublishAt)
+			case tt.want != nil && (update.PublishAt == nil || !update.PublishAt.Equal(*tt.want)):
+				t.Errorf("publish_at = %v, want %v", update.PublishAt, tt.want)
+			}
+		})
+	}
+}
+func TestToPatchUpdateRequestUnschedule(t *testing.T) {
+	publishAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	draft := &PostResponse{Title: "Title", Content: "Content"}
+	scheduled := &PostResponse{Title: "Title", Content: "Content", PublishAt: &publishAt}
+	tests := []struct {
+		name  string
+		post  *PostResponse
+		apply func(map[string]interface{}, []byte) (map[string]interface{}, error)
+		patch string
+		want  bool
+	}{
+		{name: "merge patch null unschedules", post: scheduled, apply: utils.ApplyMergePatch, patch: `{"publish_at":null}`, want: true},
+		{name: "json patch remove unschedules", post: scheduled, apply: utils.ApplyJSONPatch, patch: `[{"op":"remove","path":"/publish_at"}]`, want: true},
+		{name: "json patch replace with null unschedules", post: scheduled, apply: utils.ApplyJSONPatch, patch: `[{"op":"replace","path":"/publish_at","value":null}]`, want: true},
+		{name: "content edit keeps the schedule", post: scheduled, apply: utils.ApplyMergePatch, patch: `{"title":"New"}`, want: false},
+		{name: "merge patch null on a draft", post: draft, apply: utils.ApplyMergePatch, patch: `{"publish_at":null}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := applyToPost(t, tt.post, tt.apply, tt.patch).ToPatchUpdateRequest(tt.post)
+			if update.Unschedule != tt.want {
+				t.Errorf("Unschedule = %v, want %v", update.Unschedule, tt.want)
+			}
+			if tt.want && update.PublishAt != nil {
+				t.Errorf("PublishAt = %v, want nil", update.PublishAt)
+			}
+		})
+	}
+}
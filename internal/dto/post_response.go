@@ -2,6 +2,7 @@ package dto
 
 import (
 	"posts-api/internal/models"
+	"posts-api/pkg/utils"
 	"time"
 )
 type UserData struct {
@@ -10,28 +11,50 @@ type UserData struct {
 	Email    string `json:"email"`
 }
 type PostResponse struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	AuthorID  int64     `json:"author_id"`
-	Author    *UserData `json:"author,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           int64      `json:"id"`
+	Title        string     `json:"title"`
+	Content      string     `json:"content"`
+	AuthorID     int64      `json:"author_id"`
+	Author       *UserData  `json:"author,omitempty"`
+	Status       string     `json:"status"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+	PublishAt    *time.Time `json:"publish_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	Version      int64      `json:"version"`
+	Tags         []string   `json:"tags"`
+	CommentCount int64      `json:"comment_count"`
 }
 type PostListResponse struct {
 	Posts      []PostResponse `json:"posts"`
 	TotalCount int64          `json:"total_count"`
-	Page       int            `json:"page"`
+	Page       int            `json:"page,omitempty"`
 	PageSize   int            `json:"page_size"`
-	TotalPages int            `json:"total_pages"`
+	TotalPages int            `json:"total_pages,omitempty"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
 }
 func (pr *PostResponse) FromModel(post *models.Post) {
 	pr.ID = post.ID
 	pr.Title = post.Title
 	pr.Content = post.Content
 	pr.AuthorID = post.AuthorID
+	pr.Status = post.Status
+	pr.PublishedAt = post.PublishedAt
+	pr.PublishAt = post.PublishAt
 	pr.CreatedAt = post.CreatedAt
 	pr.UpdatedAt = post.UpdatedAt
+	pr.Version = post.Version
+	pr.CommentCount = post.CommentCount
+	pr.Tags = make([]string, len(post.Tags))
+	for i, tag := range post.Tags {
+		pr.Tags[i] = tag.Slug
+	}
+	if post.DeletedAt.Valid {
+		deletedAt := post.DeletedAt.Time
+		pr.DeletedAt = &deletedAt
+	}
 	
 	if post.AuthorName != "" && post.AuthorEmail != "" {
 		pr.Author = &UserData{
@@ -41,6 +64,11 @@ func (pr *PostResponse) FromModel(post *models.Post) {
 		}
 	}
 }
+// ETag identifies this representation of the post. It covers the comment
+// count, which changes without bumping the post's version.
+func (pr *PostResponse) ETag() string {
+	return utils.FormatETag(pr.Version, pr.CommentCount)
+}
 func (pr *PostResponse) FromModelWithUser(post *models.Post, user *UserData) {
 	pr.FromModel(post)
 	pr.Author = user
@@ -69,3 +97,21 @@ func NewPostListResponse(posts []*models.Post, totalCount int64, page, pageSize
 		TotalPages: totalPages,
 	}
 }
+func NewCursorPostListResponse(posts []*models.Post, totalCount int64, pageSize int, nextCursor, prevCursor *utils.Cursor) *PostListResponse {
+	postResponses := make([]PostResponse, len(posts))
+	for i, post := range posts {
+		postResponses[i] = *NewPostResponse(post)
+	}
+	response := &PostListResponse{
+		Posts:      postResponses,
+		TotalCount: totalCount,
+		PageSize:   pageSize,
+	}
+	if nextCursor != nil {
+		response.NextCursor = utils.EncodeCursor(*nextCursor)
+	}
+	if prevCursor != nil {
+		response.PrevCursor = utils.EncodeCursor(*prevCursor)
+	}
+	return response
+}
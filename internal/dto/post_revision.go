@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"posts-api/internal/models"
+	"posts-api/pkg/utils"
+	"time"
+)
+type PostRevisionResponse struct {
+	PostID    int64     `json:"post_id"`
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	EditedBy  int64     `json:"edited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+type PostRevisionListResponse struct {
+	Revisions  []PostRevisionResponse `json:"revisions"`
+	TotalCount int64                  `json:"total_count"`
+	Page       int                    `json:"page"`
+	PageSize   int                    `json:"page_size"`
+	TotalPages int                    `json:"total_pages"`
+}
+// PostRevisionDiffResponse compares two revisions. A nil To means the
+// post's current title and content.
+type PostRevisionDiffResponse struct {
+	PostID  int64            `json:"post_id"`
+	From    int              `json:"from"`
+	To      *int             `json:"to"`
+	Title   []utils.DiffLine `json:"title"`
+	Content []utils.DiffLine `json:"content"`
+}
+func NewPostRevisionResponse(revision *models.PostRevision) *PostRevisionResponse {
+	return &PostRevisionResponse{
+		PostID:    revision.PostID,
+		Revision:  revision.Revision,
+		Title:     revision.Title,
+		Content:   revision.Content,
+		EditedBy:  revision.EditedBy,
+		CreatedAt: revision.CreatedAt,
+	}
+}
+func NewPostRevisionListResponse(revisions []*models.PostRevision, totalCount int64, page, pageSize int) *PostRevisionListResponse {
+	responses := make([]PostRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = *NewPostRevisionResponse(revision)
+	}
+	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
+	return &PostRevisionListResponse{
+		Revisions:  responses,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+}
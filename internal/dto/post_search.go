@@ -0,0 +1,38 @@
+package dto
+
+import "posts-api/internal/models"
+// PostSearchResult is a post matched by full-text search. TitleHighlight and
+// Snippet are HTML-escaped, with matched terms wrapped in <mark></mark>.
+type PostSearchResult struct {
+	PostResponse
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+type PostSearchResponse struct {
+	Query      string             `json:"query"`
+	Results    []PostSearchResult `json:"results"`
+	TotalCount int64              `json:"total_count"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+	TotalPages int                `json:"total_pages"`
+}
+func NewPostSearchResult(post *models.Post, rank float64, titleHighlight, snippet string) PostSearchResult {
+	return PostSearchResult{
+		PostResponse:   *NewPostResponse(post),
+		Rank:           rank,
+		TitleHighlight: titleHighlight,
+		Snippet:        snippet,
+	}
+}
+func NewPostSearchResponse(query string, results []PostSearchResult, totalCount int64, page, pageSize int) *PostSearchResponse {
+	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
+	return &PostSearchResponse{
+		Query:      query,
+		Results:    results,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,9 @@
+package dto
+
+type TagResponse struct {
+	Slug      string `json:"slug"`
+	PostCount int64  `json:"post_count"`
+}
+type TagListResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
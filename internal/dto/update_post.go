@@ -7,6 +7,13 @@ import (
 type UpdatePostRequest struct {
 	Title   *string `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
 	Content *string `json:"content,omitempty" validate:"omitempty,min=1,max=10000"`
+	// Tags replaces the post's tags when set; an empty list removes them all.
+	Tags *[]string `json:"tags,omitempty" validate:"omitempty,max=10,dive,min=1,max=50"`
+	// PublishAt schedules a draft, or reschedules a scheduled post.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// Unschedule turns a scheduled post back into a draft. It is set when a
+	// PATCH clears publish_at, and has no effect on a post that isn't scheduled.
+	Unschedule bool `json:"-"`
 }
 func (req *UpdatePostRequest) UpdateModel(post *models.Post) {
 	if req.Title != nil {
@@ -18,7 +25,7 @@ func (req *UpdatePostRequest) UpdateModel(post *models.Post) {
 	post.UpdatedAt = time.Now()
 }
 func (req *UpdatePostRequest) HasChanges() bool {
-	return req.Title != nil || req.Content != nil
+	return req.Title != nil || req.Content != nil || req.Tags != nil || req.PublishAt != nil || req.Unschedule
 }
 func (req *UpdatePostRequest) Validate() error {
 	return nil
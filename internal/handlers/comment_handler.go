@@ -0,0 +1,179 @@
+package handlers
+import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"net/http"
+	"posts-api/internal/dto"
+	"posts-api/internal/middleware"
+	"posts-api/internal/services"
+	"posts-api/pkg/utils"
+	"strconv"
+)
+type CommentHandler struct {
+	commentService services.CommentService
+	validator      *validator.Validate
+}
+func NewCommentHandler(commentService services.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		validator:      validator.New(),
+	}
+}
+// GetComments lists top-level comments oldest first, each with its first few
+// replies. Pages are followed with the opaque next_cursor value.
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	postID, ok := h.parseID(w, r, "id", "post ID")
+	if !ok {
+		return
+	}
+	cursor, pageSize, ok := h.parsePage(w, r)
+	if !ok {
+		return
+	}
+	comments, err := h.commentService.GetComments(r.Context(), postID, h.viewer(r), cursor, pageSize)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Comments retrieved successfully", comments)
+}
+// GetReplies lists the replies to a top-level comment oldest first. The first
+// page continues from a comment's replies_next_cursor.
+func (h *CommentHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	postID, ok := h.parseID(w, r, "id", "post ID")
+	if !ok {
+		return
+	}
+	commentID, ok := h.parseID(w, r, "commentId", "comment ID")
+	if !ok {
+		return
+	}
+	cursor, pageSize, ok := h.parsePage(w, r)
+	if !ok {
+		return
+	}
+	replies, err := h.commentService.GetReplies(r.Context(), postID, commentID, h.viewer(r), cursor, pageSize)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Replies retrieved successfully", replies)
+}
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	postID, ok := h.parseID(w, r, "id", "post ID")
+	if !ok {
+		return
+	}
+	var createReq dto.CreateCommentRequest
+	if !h.decodeAndValidate(w, r, &createReq) {
+		return
+	}
+	user, ok := middleware.GetUserDataFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized,
+			"User not authenticated",
+			"AUTHENTICATION_ERROR",
+			"User data not found in request context")
+		return
+	}
+	comment, err := h.commentService.CreateComment(r.Context(), postID, &createReq, user)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusCreated, "Comment created successfully", comment)
+}
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	postID, commentID, actor, ok := h.parseCommentAndActor(w, r)
+	if !ok {
+		return
+	}
+	var updateReq dto.UpdateCommentRequest
+	if !h.decodeAndValidate(w, r, &updateReq) {
+		return
+	}
+	comment, err := h.commentService.UpdateComment(r.Context(), postID, commentID, &updateReq, actor)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Comment updated successfully", comment)
+}
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	postID, commentID, actor, ok := h.parseCommentAndActor(w, r)
+	if !ok {
+		return
+	}
+	if err := h.commentService.DeleteComment(r.Context(), postID, commentID, actor); err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Comment deleted successfully", nil)
+}
+func (h *CommentHandler) parseCommentAndActor(w http.ResponseWriter, r *http.Request) (int64, int64, services.Actor, bool) {
+	postID, ok := h.parseID(w, r, "id", "post ID")
+	if !ok {
+		return 0, 0, services.Actor{}, false
+	}
+	commentID, ok := h.parseID(w, r, "commentId", "comment ID")
+	if !ok {
+		return 0, 0, services.Actor{}, false
+	}
+	user, ok := middleware.GetUserDataFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized,
+			"User not authenticated",
+			"AUTHENTICATION_ERROR",
+			"User data not found in request context")
+		return 0, 0, services.Actor{}, false
+	}
+	return postID, commentID, services.NewActor(user), true
+}
+func (h *CommentHandler) parsePage(w http.ResponseWriter, r *http.Request) (*utils.Cursor, int, bool) {
+	pageSize := parsePageSize(r, 20)
+	var cursor *utils.Cursor
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		c, err := utils.DecodeCursor(cursorStr)
+		if err != nil || c.Direction != utils.CursorNext {
+			utils.WriteErrorResponse(w, http.StatusBadRequest,
+				"Invalid cursor",
+				"INVALID_CURSOR",
+				"Cursor must be a value returned in next_cursor or replies_next_cursor")
+			return nil, 0, false
+		}
+		cursor = c
+	}
+	return cursor, pageSize, true
+}
+func (h *CommentHandler) viewer(r *http.Request) services.Actor {
+	if user, ok := middleware.GetUserDataFromContext(r.Context()); ok {
+		return services.NewActor(user)
+	}
+	return services.Actor{}
+}
+func (h *CommentHandler) parseID(w http.ResponseWriter, r *http.Request, param, name string) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)[param], 10, 64)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid "+name,
+			"INVALID_PARAMETER",
+			name+" must be a valid number")
+		return 0, false
+	}
+	return id, true
+}
+func (h *CommentHandler) decodeAndValidate(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid request body",
+			"INVALID_JSON",
+			err.Error())
+		return false
+	}
+	if err := h.validator.Struct(req); err != nil {
+		utils.WriteValidationErrorResponse(w, extractValidationErrors(err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package handlers
+import (
+	"net/http"
+	"posts-api/internal/services"
+	"posts-api/pkg/utils"
+	"sync/atomic"
+)
+type HealthHandler struct {
+	healthService services.HealthService
+	userService   services.UserService
+	ready         atomic.Bool
+}
+func NewHealthHandler(healthService services.HealthService, userService services.UserService) *HealthHandler {
+	h := &HealthHandler{
+		healthService: healthService,
+		userService:   userService,
+	}
+	h.ready.Store(true)
+	return h
+}
+// SetReady flips the readiness endpoints; it is set to false when shutdown
+// begins so load balancers stop routing new requests here.
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+// Live only reports that the process is serving requests; it never checks
+// dependencies so a database outage doesn't get the pod restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSONResponse(w, http.StatusOK, map[string]interface{}{
+		"status":  "alive",
+		"service": "posts-api",
+	})
+}
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	status, body := h.readiness(r)
+	utils.WriteJSONResponse(w, status, body)
+}
+// Health is the readiness report plus token cache statistics.
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	status, body := h.readiness(r)
+	if cache, ok := h.userService.(services.CachingUserService); ok {
+		body["token_cache"] = cache.CacheStats()
+	}
+	utils.WriteJSONResponse(w, status, body)
+}
+func (h *HealthHandler) readiness(r *http.Request) (int, map[string]interface{}) {
+	body := map[string]interface{}{
+		"service": "posts-api",
+	}
+	if !h.ready.Load() {
+		body["status"] = "shutting_down"
+		return http.StatusServiceUnavailable, body
+	}
+	report := h.healthService.CheckDependencies(r.Context())
+	body["dependencies"] = report.Dependencies
+	if !report.Healthy {
+		body["status"] = "unhealthy"
+		return http.StatusServiceUnavailable, body
+	}
+	body["status"] = "healthy"
+	if report.Degraded {
+		body["status"] = "degraded"
+	}
+	return http.StatusOK, body
+}
@@ -0,0 +1,32 @@
+package handlers
+import (
+	"net/http"
+	"posts-api/pkg/utils"
+)
+// parseIfMatch reads the If-Match header. It returns a nil version when the
+// header is absent (and not required) or "*", which matches any version.
+func parseIfMatch(w http.ResponseWriter, r *http.Request, required bool) (*int64, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		if required {
+			utils.WriteErrorResponse(w, http.StatusPreconditionRequired,
+				"Precondition required",
+				"PRECONDITION_REQUIRED",
+				"Send the post's ETag in an If-Match header")
+			return nil, false
+		}
+		return nil, true
+	}
+	version, wildcard, err := utils.ParseIfMatch(header)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid If-Match header",
+			"INVALID_IF_MATCH",
+			"If-Match must be a single ETag returned by this API, or *")
+		return nil, false
+	}
+	if wildcard {
+		return nil, true
+	}
+	return &version, true
+}
@@ -0,0 +1,29 @@
+package handlers
+import (
+	"net/http"
+	"strconv"
+)
+// maxPageSize caps page_size on every listing.
+const maxPageSize = 100
+// parsePagination reads the page and page_size query parameters of an
+// offset-paginated listing. Missing or out-of-range values fall back to the
+// first page of 10.
+func parsePagination(r *http.Request) (int, int) {
+	page := 1
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	return page, parsePageSize(r, 10)
+}
+// parsePageSize reads the page_size query parameter, falling back to
+// defaultSize when it is missing or out of range.
+func parsePageSize(r *http.Request, defaultSize int) int {
+	if pageSizeStr := r.URL.Query().Get("page_size"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= maxPageSize {
+			return ps
+		}
+	}
+	return defaultSize
+}
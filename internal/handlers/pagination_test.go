@@ -0,0 +1,26 @@
+package handlers
+import (
+	"net/http/httptest"
+	"testing"
+)
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query        string
+		wantPage     int
+		wantPageSize int
+	}{
+		{query: "", wantPage: 1, wantPageSize: 10},
+		{query: "page=3&page_size=25", wantPage: 3, wantPageSize: 25},
+		{query: "page=0&page_size=0", wantPage: 1, wantPageSize: 10},
+		{query: "page=-2&page_size=101", wantPage: 1, wantPageSize: 10},
+		{query: "page=x&page_size=y", wantPage: 1, wantPageSize: 10},
+		{query: "page_size=100", wantPage: 1, wantPageSize: 100},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/posts?"+tt.query, nil)
+		page, pageSize := parsePagination(r)
+		if page != tt.wantPage || pageSize != tt.wantPageSize {
+			t.Errorf("parsePagination(%q) = %d, %d, want %d, %d", tt.query, page, pageSize, tt.wantPage, tt.wantPageSize)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package handlers
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"posts-api/internal/dto"
 	"posts-api/internal/middleware"
@@ -8,17 +12,23 @@ import (
 	"posts-api/pkg/utils"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 )
+const maxPatchBytes = 1 << 20
 type PostHandler struct {
 	postService services.PostService
 	validator   *validator.Validate
+	// requireIfMatch rejects writes that don't send If-Match with 428.
+	requireIfMatch bool
 }
-func NewPostHandler(postService services.PostService) *PostHandler {
+func NewPostHandler(postService services.PostService, requireIfMatch bool) *PostHandler {
 	return &PostHandler{
-		postService: postService,
-		validator:   validator.New(),
+		postService:    postService,
+		validator:      validator.New(),
+		requireIfMatch: requireIfMatch,
 	}
 }
 func (h *PostHandler) convertUserDTOToUserData(user *services.UserDTO) *dto.UserData {
@@ -31,6 +41,14 @@ func (h *PostHandler) convertUserDTOToUserData(user *services.UserDTO) *dto.User
 		Email:    user.Email,
 	}
 }
+// viewer returns the caller as an Actor, or the zero Actor for anonymous
+// requests on public routes.
+func (h *PostHandler) viewer(r *http.Request) services.Actor {
+	if user, ok := middleware.GetUserDataFromContext(r.Context()); ok {
+		return services.NewActor(user)
+	}
+	return services.Actor{}
+}
 func (h *PostHandler) addAuthorInfoIfOwner(r *http.Request, response *dto.PostResponse) {
 	if currentUser, ok := middleware.GetUserDataFromContext(r.Context()); ok {
 		if currentUser.ID == response.AuthorID {
@@ -58,7 +76,7 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.validator.Struct(&createReq); err != nil {
-		validationErrors := h.extractValidationErrors(err)
+		validationErrors := extractValidationErrors(err)
 		utils.WriteValidationErrorResponse(w, validationErrors)
 		return
 	}
@@ -87,11 +105,12 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	post, err := h.postService.CreatePost(&createReq, userID, token)
+	post, err := h.postService.CreatePost(r.Context(), &createReq, userID, token)
 	if err != nil {
-		utils.WriteInternalErrorResponse(w, err)
+		utils.WriteDomainErrorResponse(w, err)
 		return
 	}
+	w.Header().Set("ETag", post.ETag())
 	utils.WriteSuccessResponse(w, http.StatusCreated, "Post created successfully", post)
 }
 func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
@@ -112,41 +131,78 @@ func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 			"Post ID must be a valid number")
 		return
 	}
-	post, err := h.postService.GetPostByID(id)
+	post, err := h.postService.GetPostByID(r.Context(), id, h.viewer(r))
 	if err != nil {
-		if err.Error() == "post not found" {
-			utils.WriteNotFoundResponse(w, "Post")
-			return
-		}
-		utils.WriteInternalErrorResponse(w, err)
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	etag := post.ETag()
+	w.Header().Set("ETag", etag)
+	if match := r.Header.Get("If-None-Match"); match != "" && utils.ETagMatchesNoneMatch(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 	h.addAuthorInfoIfOwner(r, post)
 	utils.WriteSuccessResponse(w, http.StatusOK, "Post retrieved successfully", post)
 }
 func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
-	pageStr := r.URL.Query().Get("page")
-	pageSizeStr := r.URL.Query().Get("page_size")
-	page := 1
-	pageSize := 10
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
+	page, pageSize := parsePagination(r)
+	listQuery, ok := h.parseListQuery(w, r)
+	if !ok {
+		return
 	}
-	if pageSizeStr != "" {
-		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
-			pageSize = ps
+	var posts *dto.PostListResponse
+	var err error
+	if r.URL.Query().Has("cursor") {
+		if !listQuery.IsDefaultSort() {
+			utils.WriteErrorResponse(w, http.StatusBadRequest,
+				"Invalid sort",
+				"INVALID_PARAMETER",
+				"Cursor pagination only supports sort=created_at with order=desc")
+			return
 		}
+		cursor, ok := h.parseCursor(w, r)
+		if !ok {
+			return
+		}
+		posts, err = h.postService.GetAllPostsByCursor(r.Context(), listQuery, cursor, pageSize)
+	} else {
+		posts, err = h.postService.GetAllPosts(r.Context(), listQuery, page, pageSize)
 	}
-	posts, err := h.postService.GetAllPosts(page, pageSize)
 	if err != nil {
-		utils.WriteInternalErrorResponse(w, err)
+		utils.WriteDomainErrorResponse(w, err)
 		return
 	}
 	h.addAuthorInfoToList(r, posts.Posts)
 	utils.WriteSuccessResponse(w, http.StatusOK, "Posts retrieved successfully", posts)
 }
+func (h *PostHandler) SearchPosts(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Search query is required",
+			"MISSING_PARAMETER",
+			"Query parameter q must be provided")
+		return
+	}
+	if utf8.RuneCountInString(query) > 200 {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Search query too long",
+			"INVALID_PARAMETER",
+			"Query parameter q must be at most 200 characters")
+		return
+	}
+	page, pageSize := parsePagination(r)
+	results, err := h.postService.SearchPosts(r.Context(), query, page, pageSize)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	for i := range results.Results {
+		h.addAuthorInfoIfOwner(r, &results.Results[i].PostResponse)
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Search completed successfully", results)
+}
 func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr, exists := vars["id"]
@@ -165,57 +221,138 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 			"Post ID must be a valid number")
 		return
 	}
-	var updateReq dto.UpdatePostRequest
-	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+	ifMatch, ok := parseIfMatch(w, r, h.requireIfMatch)
+	if !ok {
+		return
+	}
+	// PUT replaces the post, so the body must be a complete post, validated
+	// exactly like a create.
+	var replaceReq dto.CreatePostRequest
+	if err := json.NewDecoder(r.Body).Decode(&replaceReq); err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest,
 			"Invalid request body",
 			"INVALID_JSON",
 			err.Error())
 		return
 	}
-	if err := h.validator.Struct(&updateReq); err != nil {
-		validationErrors := h.extractValidationErrors(err)
-		utils.WriteValidationErrorResponse(w, validationErrors)
+	h.replacePost(w, r, id, &replaceReq, replaceReq.ToUpdateRequest(), ifMatch)
+}
+// PatchPost applies a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902)
+// to the post's current representation and stores the result as a full
+// replacement. Without If-Match, the version the patch was applied to is
+// used, so a concurrent edit in between yields 412 rather than being lost.
+func (h *PostHandler) PatchPost(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid post ID",
+			"INVALID_PARAMETER",
+			"Post ID must be a valid number")
+		return
+	}
+	ifMatch, ok := parseIfMatch(w, r, h.requireIfMatch)
+	if !ok {
+		return
+	}
+	var applyPatch func(map[string]interface{}, []byte) (map[string]interface{}, error)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case utils.MergePatchContentType:
+		applyPatch = utils.ApplyMergePatch
+	case utils.JSONPatchContentType:
+		applyPatch = utils.ApplyJSONPatch
+	default:
+		w.Header().Set("Accept-Patch", utils.MergePatchContentType+", "+utils.JSONPatchContentType)
+		utils.WriteErrorResponse(w, http.StatusUnsupportedMediaType,
+			"Unsupported patch format",
+			"UNSUPPORTED_MEDIA_TYPE",
+			"Content-Type must be "+utils.MergePatchContentType+" or "+utils.JSONPatchContentType)
 		return
 	}
-	if !updateReq.HasChanges() {
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBytes))
+	if err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest,
-			"No changes provided",
-			"NO_CHANGES",
-			"At least one field must be provided for update")
+			"Invalid request body",
+			"INVALID_JSON",
+			err.Error())
 		return
 	}
-	if err := updateReq.Validate(); err != nil {
+	current, err := h.postService.GetPostByID(r.Context(), id, h.viewer(r))
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	if ifMatch == nil {
+		ifMatch = &current.Version
+	}
+	patched, err := applyPatch(dto.NewPostDocument(current), patch)
+	if errors.Is(err, utils.ErrPatchTestFailed) {
+		utils.WriteErrorResponse(w, http.StatusConflict,
+			"Patch test failed",
+			"PATCH_TEST_FAILED",
+			err.Error())
+		return
+	}
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid patch document",
+			"INVALID_PATCH",
+			err.Error())
+		return
+	}
+	patchedJSON, err := json.Marshal(patched)
+	if err != nil {
+		utils.WriteInternalErrorResponse(w, err)
+		return
+	}
+	var replaceReq dto.CreatePostRequest
+	decoder := json.NewDecoder(bytes.NewReader(patchedJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&replaceReq); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid patch result",
+			"INVALID_PATCH",
+			err.Error())
+		return
+	}
+	h.replacePost(w, r, id, &replaceReq, replaceReq.ToPatchUpdateRequest(current), ifMatch)
+}
+// replacePost validates a complete post representation and stores it as
+// update, the changes it was turned into.
+func (h *PostHandler) replacePost(w http.ResponseWriter, r *http.Request, id int64, replaceReq *dto.CreatePostRequest, update *dto.UpdatePostRequest, ifMatch *int64) {
+	if err := h.validator.Struct(replaceReq); err != nil {
+		validationErrors := extractValidationErrors(err)
+		utils.WriteValidationErrorResponse(w, validationErrors)
+		return
+	}
+	if err := replaceReq.Validate(); err != nil {
 		utils.WriteErrorResponse(w, http.StatusBadRequest,
 			"Validation failed",
 			"BUSINESS_VALIDATION_ERROR",
 			err.Error())
 		return
 	}
-	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if replaceReq.Status != "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Status is read-only",
+			"STATUS_READ_ONLY",
+			"Use the publish, unpublish and archive endpoints to change a post's status")
+		return
+	}
+	user, ok := middleware.GetUserDataFromContext(r.Context())
 	if !ok {
 		utils.WriteErrorResponse(w, http.StatusUnauthorized,
 			"User not authenticated",
 			"AUTHENTICATION_ERROR",
-			"User ID not found in request context")
+			"User data not found in request context")
 		return
 	}
-	post, err := h.postService.UpdatePost(id, &updateReq, userID)
+	post, err := h.postService.UpdatePost(r.Context(), id, update, services.NewActor(user), ifMatch)
 	if err != nil {
-		if err.Error() == "post not found" {
-			utils.WriteNotFoundResponse(w, "Post")
-			return
-		}
-		if err.Error() == "unauthorized: only the author can update this post" {
-			utils.WriteErrorResponse(w, http.StatusForbidden,
-				"Access denied",
-				"FORBIDDEN",
-				"You can only update your own posts")
-			return
-		}
-		utils.WriteInternalErrorResponse(w, err)
+		utils.WriteDomainErrorResponse(w, err)
 		return
 	}
+	w.Header().Set("ETag", post.ETag())
 	h.addAuthorInfoIfOwner(r, post)
 	utils.WriteSuccessResponse(w, http.StatusOK, "Post updated successfully", post)
 }
@@ -237,30 +374,24 @@ func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 			"Post ID must be a valid number")
 		return
 	}
-	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	user, ok := middleware.GetUserDataFromContext(r.Context())
 	if !ok {
 		utils.WriteErrorResponse(w, http.StatusUnauthorized,
 			"User not authenticated",
 			"AUTHENTICATION_ERROR",
-			"User ID not found in request context")
+			"User data not found in request context")
 		return
 	}
-	err = h.postService.DeletePost(id, userID)
-	if err != nil {		if err.Error() == "post not found" {
-			utils.WriteNotFoundResponse(w, "Post")
-			return
-		}
-		if err.Error() == "unauthorized: only the author can delete this post" {
-			utils.WriteErrorResponse(w, http.StatusForbidden,
-				"Access denied",
-				"FORBIDDEN",
-				"You can only delete your own posts")
-			return
-		}
-		utils.WriteInternalErrorResponse(w, err)
+	ifMatch, ok := parseIfMatch(w, r, h.requireIfMatch)
+	if !ok {
+		return
+	}
+	err = h.postService.DeletePost(r.Context(), id, services.NewActor(user), ifMatch)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
 		return
 	}
-	utils.WriteSuccessResponse(w, http.StatusOK, "Post deleted successfully", nil)
+	utils.WriteSuccessResponse(w, http.StatusOK, "Post moved to trash", nil)
 }
 func (h *PostHandler) GetPostsByAuthor(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -280,51 +411,249 @@ func (h *PostHandler) GetPostsByAuthor(w http.ResponseWriter, r *http.Request) {
 			"Author ID must be a valid number")
 		return
 	}
-	pageStr := r.URL.Query().Get("page")
-	pageSizeStr := r.URL.Query().Get("page_size")
-	page := 1
-	pageSize := 10
-	if pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-	if pageSizeStr != "" {
-		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
-			pageSize = ps
+	page, pageSize := parsePagination(r)
+	var posts *dto.PostListResponse
+	if r.URL.Query().Has("cursor") {
+		cursor, ok := h.parseCursor(w, r)
+		if !ok {
+			return
 		}
+		posts, err = h.postService.GetPostsByAuthorByCursor(r.Context(), authorID, cursor, pageSize)
+	} else {
+		posts, err = h.postService.GetPostsByAuthor(r.Context(), authorID, page, pageSize)
 	}
-	posts, err := h.postService.GetPostsByAuthor(authorID, page, pageSize)
 	if err != nil {
-		utils.WriteInternalErrorResponse(w, err)
+		utils.WriteDomainErrorResponse(w, err)
 		return
 	}
 	h.addAuthorInfoToList(r, posts.Posts)
 	utils.WriteSuccessResponse(w, http.StatusOK, "Author posts retrieved successfully", posts)
 }
-func (h *PostHandler) extractValidationErrors(err error) []utils.ValidationError {
-	var validationErrors []utils.ValidationError
-	if validatorErrs, ok := err.(validator.ValidationErrors); ok {
-		for _, e := range validatorErrs {
-			validationErrors = append(validationErrors, utils.ValidationError{
-				Field:   e.Field(),
-				Message: h.getValidationMessage(e),
-			})
-		}
+func (h *PostHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized,
+			"User not authenticated",
+			"AUTHENTICATION_ERROR",
+			"User ID not found in request context")
+		return
+	}
+	page, pageSize := parsePagination(r)
+	posts, err := h.postService.GetTrashedPosts(r.Context(), userID, page, pageSize)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
 	}
-	return validationErrors
+	h.addAuthorInfoToList(r, posts.Posts)
+	utils.WriteSuccessResponse(w, http.StatusOK, "Trashed posts retrieved successfully", posts)
 }
-func (h *PostHandler) getValidationMessage(e validator.FieldError) string {
-	switch e.Tag() {
-	case "required":
-		return e.Field() + " is required"
-	case "min":
-		return e.Field() + " must be at least " + e.Param() + " characters"
-	case "max":
-		return e.Field() + " must be at most " + e.Param() + " characters"
+func (h *PostHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idStr, exists := vars["id"]
+	if !exists {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Post ID is required",
+			"MISSING_PARAMETER",
+			"Post ID must be provided in the URL")
+		return
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid post ID",
+			"INVALID_PARAMETER",
+			"Post ID must be a valid number")
+		return
+	}
+	user, ok := middleware.GetUserDataFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized,
+			"User not authenticated",
+			"AUTHENTICATION_ERROR",
+			"User data not found in request context")
+		return
+	}
+	post, err := h.postService.RestorePost(r.Context(), id, services.NewActor(user))
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	w.Header().Set("ETag", post.ETag())
+	h.addAuthorInfoIfOwner(r, post)
+	utils.WriteSuccessResponse(w, http.StatusOK, "Post restored successfully", post)
+}
+func (h *PostHandler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	h.transitionPost(w, r, services.TransitionPublish, "Post published successfully")
+}
+func (h *PostHandler) UnpublishPost(w http.ResponseWriter, r *http.Request) {
+	h.transitionPost(w, r, services.TransitionUnpublish, "Post unpublished successfully")
+}
+func (h *PostHandler) ArchivePost(w http.ResponseWriter, r *http.Request) {
+	h.transitionPost(w, r, services.TransitionArchive, "Post archived successfully")
+}
+func (h *PostHandler) transitionPost(w http.ResponseWriter, r *http.Request, transition services.PostTransition, message string) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid post ID",
+			"INVALID_PARAMETER",
+			"Post ID must be a valid number")
+		return
+	}
+	user, ok := middleware.GetUserDataFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized,
+			"User not authenticated",
+			"AUTHENTICATION_ERROR",
+			"User data not found in request context")
+		return
+	}
+	ifMatch, ok := parseIfMatch(w, r, h.requireIfMatch)
+	if !ok {
+		return
+	}
+	post, err := h.postService.TransitionPost(r.Context(), id, transition, services.NewActor(user), ifMatch)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	w.Header().Set("ETag", post.ETag())
+	h.addAuthorInfoIfOwner(r, post)
+	utils.WriteSuccessResponse(w, http.StatusOK, message, post)
+}
+func (h *PostHandler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized,
+			"User not authenticated",
+			"AUTHENTICATION_ERROR",
+			"User ID not found in request context")
+		return
+	}
+	page, pageSize := parsePagination(r)
+	posts, err := h.postService.GetDrafts(r.Context(), userID, page, pageSize)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	h.addAuthorInfoToList(r, posts.Posts)
+	utils.WriteSuccessResponse(w, http.StatusOK, "Drafts retrieved successfully", posts)
+}
+// parseListQuery reads the sort and filter query parameters of the post
+// listing, writing a 400 response and returning false on invalid input.
+func (h *PostHandler) parseListQuery(w http.ResponseWriter, r *http.Request) (*dto.PostListQuery, bool) {
+	values := r.URL.Query()
+	listQuery := &dto.PostListQuery{}
+	if sort := values.Get("sort"); sort != "" {
+		if !dto.PostSortFields[sort] {
+			utils.WriteErrorResponse(w, http.StatusBadRequest,
+				"Invalid sort",
+				"INVALID_PARAMETER",
+				"sort must be one of: created_at, updated_at, title")
+			return nil, false
+		}
+		listQuery.Sort = sort
+	}
+	if order := strings.ToLower(values.Get("order")); order != "" {
+		if order != dto.SortOrderAsc && order != dto.SortOrderDesc {
+			utils.WriteErrorResponse(w, http.StatusBadRequest,
+				"Invalid order",
+				"INVALID_PARAMETER",
+				"order must be asc or desc")
+			return nil, false
+		}
+		listQuery.Order = order
+	}
+	for _, param := range values["author_id"] {
+		for _, idStr := range strings.Split(param, ",") {
+			authorID, err := strconv.ParseInt(strings.TrimSpace(idStr), 10, 64)
+			if err != nil || authorID <= 0 {
+				utils.WriteErrorResponse(w, http.StatusBadRequest,
+					"Invalid author ID",
+					"INVALID_PARAMETER",
+					"author_id must be a positive number")
+				return nil, false
+			}
+			listQuery.AuthorIDs = append(listQuery.AuthorIDs, authorID)
+		}
+	}
+	seenTags := make(map[string]bool)
+	for _, param := range values["tag"] {
+		for _, name := range strings.Split(param, ",") {
+			slug := utils.Slugify(name)
+			if slug == "" {
+				utils.WriteErrorResponse(w, http.StatusBadRequest,
+					"Invalid tag",
+					"INVALID_PARAMETER",
+					"tag must contain letters or digits")
+				return nil, false
+			}
+			if !seenTags[slug] {
+				seenTags[slug] = true
+				listQuery.Tags = append(listQuery.Tags, slug)
+			}
+		}
+	}
+	switch mode := strings.ToLower(values.Get("tag_mode")); mode {
+	case "", "any":
+	case "all":
+		listQuery.MatchAllTags = true
 	default:
-		return e.Field() + " is invalid"
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid tag_mode",
+			"INVALID_PARAMETER",
+			"tag_mode must be any or all")
+		return nil, false
+	}
+	timeParams := []struct {
+		name   string
+		target **time.Time
+	}{
+		{"created_after", &listQuery.CreatedAfter},
+		{"created_before", &listQuery.CreatedBefore},
+		{"updated_since", &listQuery.UpdatedSince},
+	}
+	for _, param := range timeParams {
+		str := values.Get(param.name)
+		if str == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest,
+				"Invalid "+param.name,
+				"INVALID_PARAMETER",
+				param.name+" must be an RFC 3339 timestamp")
+			return nil, false
+		}
+		*param.target = &t
+	}
+	if listQuery.CreatedAfter != nil && listQuery.CreatedBefore != nil &&
+		!listQuery.CreatedAfter.Before(*listQuery.CreatedBefore) {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid date range",
+			"INVALID_PARAMETER",
+			"created_after must be earlier than created_before")
+		return nil, false
+	}
+	return listQuery, true
+}
+// parseCursor decodes the cursor query parameter. An empty cursor starts a
+// cursor-paginated listing from the newest post.
+func (h *PostHandler) parseCursor(w http.ResponseWriter, r *http.Request) (*utils.Cursor, bool) {
+	cursorStr := r.URL.Query().Get("cursor")
+	if cursorStr == "" {
+		return nil, true
+	}
+	cursor, err := utils.DecodeCursor(cursorStr)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid cursor",
+			"INVALID_CURSOR",
+			"Cursor must be a value returned in next_cursor or prev_cursor")
+		return nil, false
 	}
+	return cursor, true
 }
 func (h *PostHandler) extractTokenFromRequest(r *http.Request) string {
 	authHeader := r.Header.Get("Authorization")
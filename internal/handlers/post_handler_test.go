@@ -0,0 +1,48 @@
+package handlers
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"posts-api/internal/dto"
+	"posts-api/internal/services"
+	"strings"
+	"testing"
+)
+// stubPostService answers searches with an empty result and records the
+// query it was given. Other methods are not expected to be called.
+type stubPostService struct {
+	services.PostService
+	query string
+}
+func (s *stubPostService) SearchPosts(ctx context.Context, query string, page, pageSize int) (*dto.PostSearchResponse, error) {
+	s.query = query
+	return &dto.PostSearchResponse{Query: query, Page: page, PageSize: pageSize}, nil
+}
+func TestSearchPostsQueryLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{name: "200 ASCII characters", query: strings.Repeat("a", 200), status: http.StatusOK},
+		{name: "201 ASCII characters", query: strings.Repeat("a", 201), status: http.StatusBadRequest},
+		{name: "200 multi-byte characters", query: strings.Repeat("日", 200), status: http.StatusOK},
+		{name: "201 multi-byte characters", query: strings.Repeat("é", 201), status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubPostService{}
+			handler := NewPostHandler(service, false)
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/posts/search?q="+url.QueryEscape(tt.query), nil)
+			handler.SearchPosts(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status == http.StatusOK && service.query != tt.query {
+				t.Errorf("service got query %q, want %q", service.query, tt.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"posts-api/internal/middleware"
+	"posts-api/internal/services"
+	"posts-api/pkg/utils"
+	"strconv"
+)
+type RevisionHandler struct {
+	revisionService services.RevisionService
+	// requireIfMatch rejects restores that don't send If-Match with 428.
+	requireIfMatch bool
+}
+func NewRevisionHandler(revisionService services.RevisionService, requireIfMatch bool) *RevisionHandler {
+	return &RevisionHandler{
+		revisionService: revisionService,
+		requireIfMatch:  requireIfMatch,
+	}
+}
+func (h *RevisionHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	postID, actor, ok := h.parsePostAndActor(w, r)
+	if !ok {
+		return
+	}
+	page, pageSize := parsePagination(r)
+	revisions, err := h.revisionService.GetRevisions(r.Context(), postID, actor, page, pageSize)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Revisions retrieved successfully", revisions)
+}
+func (h *RevisionHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	postID, actor, ok := h.parsePostAndActor(w, r)
+	if !ok {
+		return
+	}
+	revision, ok := h.parseRevision(w, "revision", mux.Vars(r)["rev"])
+	if !ok {
+		return
+	}
+	rev, err := h.revisionService.GetRevision(r.Context(), postID, revision, actor)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Revision retrieved successfully", rev)
+}
+// DiffRevisions compares ?from= with ?to=, or with the current post when to
+// is omitted.
+func (h *RevisionHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	postID, actor, ok := h.parsePostAndActor(w, r)
+	if !ok {
+		return
+	}
+	fromStr := r.URL.Query().Get("from")
+	if fromStr == "" {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Revision to compare from is required",
+			"MISSING_PARAMETER",
+			"Query parameter from must be provided")
+		return
+	}
+	from, ok := h.parseRevision(w, "from", fromStr)
+	if !ok {
+		return
+	}
+	var to *int
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		rev, ok := h.parseRevision(w, "to", toStr)
+		if !ok {
+			return
+		}
+		to = &rev
+	}
+	diff, err := h.revisionService.DiffRevisions(r.Context(), postID, from, to, actor)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Revision diff computed successfully", diff)
+}
+func (h *RevisionHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	postID, actor, ok := h.parsePostAndActor(w, r)
+	if !ok {
+		return
+	}
+	revision, ok := h.parseRevision(w, "revision", mux.Vars(r)["rev"])
+	if !ok {
+		return
+	}
+	ifMatch, ok := parseIfMatch(w, r, h.requireIfMatch)
+	if !ok {
+		return
+	}
+	post, err := h.revisionService.RestoreRevision(r.Context(), postID, revision, actor, ifMatch)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	w.Header().Set("ETag", post.ETag())
+	utils.WriteSuccessResponse(w, http.StatusOK, "Revision restored successfully", post)
+}
+func (h *RevisionHandler) parsePostAndActor(w http.ResponseWriter, r *http.Request) (int64, services.Actor, bool) {
+	postID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid post ID",
+			"INVALID_PARAMETER",
+			"Post ID must be a valid number")
+		return 0, services.Actor{}, false
+	}
+	user, ok := middleware.GetUserDataFromContext(r.Context())
+	if !ok {
+		utils.WriteErrorResponse(w, http.StatusUnauthorized,
+			"User not authenticated",
+			"AUTHENTICATION_ERROR",
+			"User data not found in request context")
+		return 0, services.Actor{}, false
+	}
+	return postID, services.NewActor(user), true
+}
+func (h *RevisionHandler) parseRevision(w http.ResponseWriter, name, value string) (int, bool) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		utils.WriteErrorResponse(w, http.StatusBadRequest,
+			"Invalid "+name,
+			"INVALID_PARAMETER",
+			name+" must be a positive revision number")
+		return 0, false
+	}
+	return revision, true
+}
@@ -0,0 +1,29 @@
+package handlers
+import (
+	"net/http"
+	"posts-api/internal/services"
+	"posts-api/pkg/utils"
+	"strconv"
+)
+type TagHandler struct {
+	tagService services.TagService
+}
+func NewTagHandler(tagService services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+	tags, err := h.tagService.GetTags(r.Context(), limit)
+	if err != nil {
+		utils.WriteDomainErrorResponse(w, err)
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, "Tags retrieved successfully", tags)
+}
@@ -0,0 +1,44 @@
+package handlers
+import (
+	"posts-api/pkg/utils"
+	"reflect"
+	"strings"
+	"github.com/go-playground/validator/v10"
+)
+func extractValidationErrors(err error) []utils.ValidationError {
+	var validationErrors []utils.ValidationError
+	if validatorErrs, ok := err.(validator.ValidationErrors); ok {
+		for _, e := range validatorErrs {
+			validationErrors = append(validationErrors, utils.ValidationError{
+				Field:   e.Field(),
+				Message: getValidationMessage(e),
+			})
+		}
+	}
+	return validationErrors
+}
+func getValidationMessage(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return e.Field() + " is required"
+	case "min":
+		return strings.TrimSpace(e.Field() + " must be at least " + e.Param() + " " + validationUnit(e))
+	case "max":
+		return strings.TrimSpace(e.Field() + " must be at most " + e.Param() + " " + validationUnit(e))
+	case "oneof":
+		return e.Field() + " must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
+	default:
+		return e.Field() + " is invalid"
+	}
+}
+// validationUnit names what a min/max limit counts; numbers need none.
+func validationUnit(e validator.FieldError) string {
+	switch e.Kind() {
+	case reflect.Slice:
+		return "items"
+	case reflect.String:
+		return "characters"
+	default:
+		return ""
+	}
+}
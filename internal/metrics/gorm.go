@@ -0,0 +1,60 @@
+package metrics
+import (
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+const gormStartKey = "metrics:start_time"
+// InstrumentGORM registers callbacks that time every statement GORM runs.
+func InstrumentGORM(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []func() error{
+		func() error { return cb.Create().Before("gorm:create").Register("metrics:before_create", startTimer) },
+		func() error {
+			return cb.Create().After("gorm:create").Register("metrics:after_create", observe("create"))
+		},
+		func() error { return cb.Query().Before("gorm:query").Register("metrics:before_query", startTimer) },
+		func() error { return cb.Query().After("gorm:query").Register("metrics:after_query", observe("query")) },
+		func() error { return cb.Update().Before("gorm:update").Register("metrics:before_update", startTimer) },
+		func() error {
+			return cb.Update().After("gorm:update").Register("metrics:after_update", observe("update"))
+		},
+		func() error { return cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer) },
+		func() error {
+			return cb.Delete().After("gorm:delete").Register("metrics:after_delete", observe("delete"))
+		},
+		func() error { return cb.Row().Before("gorm:row").Register("metrics:before_row", startTimer) },
+		func() error { return cb.Row().After("gorm:row").Register("metrics:after_row", observe("row")) },
+		func() error { return cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer) },
+		func() error { return cb.Raw().After("gorm:raw").Register("metrics:after_raw", observe("raw")) },
+	}
+	for _, register := range registrations {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+func observe(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package metrics
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+const namespace = "posts_api"
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template, method and status code.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	dbQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "GORM statement latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	dbQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "GORM statements that returned an error, excluding record not found.",
+	}, []string{"operation", "table"})
+	usersAPIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "users_api_requests_total",
+		Help:      "Calls to the Users API by operation and outcome.",
+	}, []string{"operation", "outcome"})
+	usersAPIDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "users_api_request_duration_seconds",
+		Help:      "Users API call latency by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+func ObserveHTTPRequest(method, route string, status int, dur time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(dur.Seconds())
+}
+// ObserveUsersAPICall records one attempt against the Users API. outcome is
+// the HTTP status code, or "error" when no response was received.
+func ObserveUsersAPICall(operation, outcome string, dur time.Duration) {
+	usersAPIRequests.WithLabelValues(operation, outcome).Inc()
+	usersAPIDuration.WithLabelValues(operation).Observe(dur.Seconds())
+}
+// MustRegister exposes additional collectors, such as database pool or
+// cache statistics, on the metrics endpoint.
+func MustRegister(collectors ...prometheus.Collector) {
+	prometheus.MustRegister(collectors...)
+}
+// TokenCacheStats is a snapshot of the token validation cache counters.
+type TokenCacheStats struct {
+	Hits         uint64
+	NegativeHits uint64
+	Misses       uint64
+	Evictions    uint64
+	Size         int
+}
+// RegisterTokenCache exports the token cache counters, read from stats at
+// scrape time.
+func RegisterTokenCache(stats func() TokenCacheStats) {
+	counter := func(name, help string, value func(TokenCacheStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "token_cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return float64(value(stats())) })
+	}
+	MustRegister(
+		counter("hits_total", "Token validations served from the cache.",
+			func(s TokenCacheStats) uint64 { return s.Hits }),
+		counter("negative_hits_total", "Rejected tokens served from the cache.",
+			func(s TokenCacheStats) uint64 { return s.NegativeHits }),
+		counter("misses_total", "Token validations forwarded to the Users API.",
+			func(s TokenCacheStats) uint64 { return s.Misses }),
+		counter("evictions_total", "Entries evicted because the cache was full.",
+			func(s TokenCacheStats) uint64 { return s.Evictions }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "token_cache",
+			Name:      "entries",
+			Help:      "Entries currently held in the token cache.",
+		}, func() float64 { return float64(stats().Size) }),
+	)
+}
@@ -2,6 +2,7 @@ package middleware
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 	"posts-api/internal/services"
 	"posts-api/pkg/utils"
@@ -13,72 +14,124 @@ const (
 	UserIDKey   AuthContextKey = "userID"
 	UserDataKey AuthContextKey = "userData"
 )
+// authHeaderError describes why a request carries no usable Bearer token.
+type authHeaderError struct {
+	message string
+	code    string
+	details string
+}
+func (e *authHeaderError) Error() string {
+	return e.details
+}
+var (
+	errMissingToken = &authHeaderError{
+		message: "Authorization header required",
+		code:    "MISSING_TOKEN",
+		details: "Authorization header with Bearer token is required",
+	}
+	errTokenFormat = &authHeaderError{
+		message: "Invalid authorization header format",
+		code:    "INVALID_TOKEN_FORMAT",
+		details: "Authorization header must be in format: Bearer <token>",
+	}
+	errEmptyToken = &authHeaderError{
+		message: "Token is required",
+		code:    "EMPTY_TOKEN",
+		details: "JWT token cannot be empty",
+	}
+)
+// bearerToken extracts the token from a "Bearer <token>" Authorization
+// header.
+func bearerToken(r *http.Request) (string, *authHeaderError) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", errMissingToken
+	}
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", errTokenFormat
+	}
+	if parts[1] == "" {
+		return "", errEmptyToken
+	}
+	return parts[1], nil
+}
+func writeAuthHeaderError(w http.ResponseWriter, err *authHeaderError) {
+	utils.WriteErrorResponse(w, http.StatusUnauthorized, err.message, err.code, err.details)
+}
+// withUser stores the authenticated user in the request context.
+func withUser(r *http.Request, user *services.UserDTO) *http.Request {
+	ctx := context.WithValue(r.Context(), UserIDKey, user.ID)
+	ctx = context.WithValue(ctx, UserDataKey, user)
+	return r.WithContext(ctx)
+}
 func JWTMiddleware(userService services.UserService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				utils.WriteErrorResponse(w, http.StatusUnauthorized,
-					"Authorization header required",
-					"MISSING_TOKEN",
-					"Authorization header with Bearer token is required")
+			token, headerErr := bearerToken(r)
+			if headerErr != nil {
+				writeAuthHeaderError(w, headerErr)
 				return
 			}
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				utils.WriteErrorResponse(w, http.StatusUnauthorized,
-					"Invalid authorization header format",
-					"INVALID_TOKEN_FORMAT",
-					"Authorization header must be in format: Bearer <token>")
+			// Validate token with Users API
+			user, err := userService.ValidateToken(r.Context(), token)
+			if errors.Is(err, services.ErrUpstreamUnavailable) {
+				utils.WriteDomainErrorResponse(w, err)
 				return
 			}
-			token := parts[1]
-			if token == "" {
-				utils.WriteErrorResponse(w, http.StatusUnauthorized,
-					"Token is required",
-					"EMPTY_TOKEN",
-					"JWT token cannot be empty")
-				return
-			}			// Validate token with Users API
-			user, err := userService.ValidateToken(token)
 			if err != nil {
+				details := "The token could not be validated"
+				if utils.VerboseErrors() {
+					details = err.Error()
+				}
 				utils.WriteErrorResponse(w, http.StatusUnauthorized,
 					"Invalid token",
 					"INVALID_TOKEN",
-					err.Error())
+					details)
 				return
 			}
-			ctx := context.WithValue(r.Context(), UserIDKey, user.ID)
-			ctx = context.WithValue(ctx, UserDataKey, user)
-			r = r.WithContext(ctx)
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, withUser(r, user))
+		})
+	}
+}
+// OptionalJWTMiddleware authenticates requests that carry a Bearer token and
+// lets anonymous ones through, so public routes can tailor responses to the
+// caller. A token that is malformed, rejected or can't be checked while the
+// Users API is unavailable is ignored and the request is served anonymously:
+// it never sees more than it would without one.
+func OptionalJWTMiddleware(userService services.UserService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, headerErr := bearerToken(r)
+			if headerErr == errMissingToken {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if headerErr != nil {
+				slog.DebugContext(r.Context(), "Serving request anonymously, malformed authorization header", "error", headerErr)
+				next.ServeHTTP(w, r)
+				return
+			}
+			user, err := userService.ValidateToken(r.Context(), token)
+			if errors.Is(err, services.ErrUpstreamUnavailable) {
+				slog.WarnContext(r.Context(), "Serving request anonymously, token validation unavailable", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				slog.DebugContext(r.Context(), "Serving request anonymously, token rejected", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, withUser(r, user))
 		})
 	}
 }
 func JWT(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			utils.WriteErrorResponse(w, http.StatusUnauthorized,
-				"Authorization header required",
-				"MISSING_TOKEN",
-				"Authorization header with Bearer token is required")
-			return
-		}
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.WriteErrorResponse(w, http.StatusUnauthorized,
-				"Invalid authorization header format",
-				"INVALID_TOKEN_FORMAT",
-				"Authorization header must be in format: Bearer <token>")
-			return
-		}
-		token := parts[1]
-		if token == "" {
-			utils.WriteErrorResponse(w, http.StatusUnauthorized,
-				"Token is required",
-				"EMPTY_TOKEN",
-				"JWT token cannot be empty")
+		token, headerErr := bearerToken(r)
+		if headerErr != nil {
+			writeAuthHeaderError(w, headerErr)
 			return
 		}
 		userID, err := validateTokenPlaceholder(token)
@@ -0,0 +1,68 @@
+package middleware
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"posts-api/internal/services"
+	"testing"
+)
+type stubUserService struct {
+	err error
+}
+func (s stubUserService) ValidateToken(ctx context.Context, token string) (*services.UserDTO, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if token != "good" {
+		return nil, services.ErrInvalidToken
+	}
+	return &services.UserDTO{ID: 7}, nil
+}
+func (s stubUserService) GetUserFromToken(ctx context.Context, token string) (*services.UserDTO, error) {
+	return s.ValidateToken(ctx, token)
+}
+func TestAuthMiddlewares(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		upstreamErr  error
+		wantRequired int
+		wantOptional int64
+	}{
+		{name: "no header", wantRequired: http.StatusUnauthorized},
+		{name: "valid token", header: "Bearer good", wantRequired: http.StatusOK, wantOptional: 7},
+		{name: "wrong scheme", header: "Basic good", wantRequired: http.StatusUnauthorized},
+		{name: "empty token", header: "Bearer ", wantRequired: http.StatusUnauthorized},
+		{name: "rejected token", header: "Bearer bad", wantRequired: http.StatusUnauthorized},
+		{name: "users API unavailable", header: "Bearer good", upstreamErr: services.ErrUpstreamUnavailable, wantRequired: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userService := stubUserService{err: tt.upstreamErr}
+			var userID int64
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				userID, _ = GetUserIDFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			JWTMiddleware(userService)(next).ServeHTTP(rec, req)
+			if rec.Code != tt.wantRequired {
+				t.Errorf("JWTMiddleware status = %d, want %d", rec.Code, tt.wantRequired)
+			}
+			// Public routes never fail on the token; they just don't see a user.
+			userID = 0
+			rec = httptest.NewRecorder()
+			OptionalJWTMiddleware(userService)(next).ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Errorf("OptionalJWTMiddleware status = %d, want 200", rec.Code)
+			}
+			if userID != tt.wantOptional {
+				t.Errorf("OptionalJWTMiddleware user = %d, want %d", userID, tt.wantOptional)
+			}
+		})
+	}
+}
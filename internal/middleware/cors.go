@@ -7,13 +7,15 @@ type CORSConfig struct {
 	AllowedOrigins []string
 	AllowedMethods []string
 	AllowedHeaders []string
+	ExposedHeaders []string
 	MaxAge         int
 }
 func DefaultCORSConfig() CORSConfig {
 	return CORSConfig{
 		AllowedOrigins: []string{"*"}, // Allow all origins in development
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Requested-With", "Accept", "Origin", "X-CSRF-Token"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Requested-With", "Accept", "Origin", "X-CSRF-Token", "X-Request-ID", "If-Match", "If-None-Match"},
+		ExposedHeaders: []string{"ETag", "X-Request-ID"},
 		MaxAge:         86400, // 24 hours
 	}
 }
@@ -22,6 +24,7 @@ func NewCORSMiddleware(config CORSConfig) func(http.Handler) http.Handler {
 		handlers.AllowedOrigins(config.AllowedOrigins),
 		handlers.AllowedMethods(config.AllowedMethods),
 		handlers.AllowedHeaders(config.AllowedHeaders),
+		handlers.ExposedHeaders(config.ExposedHeaders),
 		handlers.MaxAge(config.MaxAge),
 	)
 }
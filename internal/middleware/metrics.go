@@ -0,0 +1,34 @@
+package middleware
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"posts-api/internal/metrics"
+	"time"
+)
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+// MetricsMiddleware records request counts and latency labelled by the mux
+// route template, so /posts/1 and /posts/2 share one series. Requests that
+// match no route are labelled "unmatched"; mux only runs them through the
+// router's NotFoundHandler and MethodNotAllowedHandler, which must be wrapped
+// as well.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if tmpl, err := current.GetPathTemplate(); err == nil {
+				route = tmpl
+			}
+		}
+		metrics.ObserveHTTPRequest(r.Method, route, recorder.status, time.Since(start))
+	})
+}
@@ -0,0 +1,11 @@
+-- IF NOT EXISTS keeps this a no-op on databases created by GORM AutoMigrate.
+CREATE TABLE IF NOT EXISTS posts (
+    id           BIGSERIAL PRIMARY KEY,
+    title        TEXT        NOT NULL,
+    content      TEXT        NOT NULL,
+    author_id    BIGINT      NOT NULL,
+    author_name  TEXT        NOT NULL,
+    author_email TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ
+);
@@ -0,0 +1,5 @@
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS chk_posts_content_length,
+    DROP CONSTRAINT IF EXISTS chk_posts_title_length;
+DROP INDEX IF EXISTS idx_posts_created_at_id;
+DROP INDEX IF EXISTS idx_posts_author_id_created_at;
@@ -0,0 +1,28 @@
+-- Author listings: WHERE author_id = ? ORDER BY created_at DESC, id DESC.
+CREATE INDEX IF NOT EXISTS idx_posts_author_id_created_at ON posts (author_id, created_at DESC, id DESC);
+-- Global listings and keyset pagination on (created_at, id). Both columns
+-- share a direction so the index also serves the ascending "prev" scan.
+CREATE INDEX IF NOT EXISTS idx_posts_created_at_id ON posts (created_at DESC, id DESC);
+-- Mirror the CreatePostRequest/UpdatePostRequest validator limits. The
+-- constraints are added NOT VALID so legacy rows that break them can't fail
+-- the migration and block startup; new writes are checked either way.
+ALTER TABLE posts
+    ADD CONSTRAINT chk_posts_title_length CHECK (char_length(title) BETWEEN 1 AND 255) NOT VALID,
+    ADD CONSTRAINT chk_posts_content_length CHECK (char_length(content) BETWEEN 1 AND 10000) NOT VALID;
+-- Existing rows are validated when they all pass. Otherwise the constraint
+-- stays NOT VALID until the offending posts are fixed and it is validated
+-- by hand.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM posts WHERE char_length(title) NOT BETWEEN 1 AND 255) THEN
+        RAISE WARNING 'posts has titles outside 1-255 characters; chk_posts_title_length left NOT VALID';
+    ELSE
+        ALTER TABLE posts VALIDATE CONSTRAINT chk_posts_title_length;
+    END IF;
+    IF EXISTS (SELECT 1 FROM posts WHERE char_length(content) NOT BETWEEN 1 AND 10000) THEN
+        RAISE WARNING 'posts has content outside 1-10000 characters; chk_posts_content_length left NOT VALID';
+    ELSE
+        ALTER TABLE posts VALIDATE CONSTRAINT chk_posts_content_length;
+    END IF;
+END
+$$;
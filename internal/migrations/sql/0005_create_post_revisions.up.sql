@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id         BIGSERIAL PRIMARY KEY,
+    post_id    BIGINT      NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    revision   INTEGER     NOT NULL,
+    title      TEXT        NOT NULL,
+    content    TEXT        NOT NULL,
+    edited_by  BIGINT      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_post_revisions_post_id_revision UNIQUE (post_id, revision)
+);
+-- Revisions are history: they may only disappear together with their post.
+CREATE OR REPLACE FUNCTION post_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'post_revisions rows are immutable';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER trg_post_revisions_immutable
+    BEFORE UPDATE ON post_revisions
+    FOR EACH ROW EXECUTE FUNCTION post_revisions_immutable();
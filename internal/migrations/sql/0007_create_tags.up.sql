@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id         BIGSERIAL PRIMARY KEY,
+    slug       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_tags_slug UNIQUE (slug),
+    CONSTRAINT chk_tags_slug CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$' AND char_length(slug) <= 50)
+);
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id BIGINT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    tag_id  BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+-- The primary key serves lookups by post; this one serves ?tag= filters.
+CREATE INDEX IF NOT EXISTS idx_post_tags_tag_id_post_id ON post_tags (tag_id, post_id);
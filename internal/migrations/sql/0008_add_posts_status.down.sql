@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_posts_published_created_at_id;
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS chk_posts_status,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,12 @@
+-- Posts that existed before the lifecycle was introduced were all public,
+-- so they start out published with their creation time as published_at.
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+UPDATE posts SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+ALTER TABLE posts
+    ALTER COLUMN status SET DEFAULT 'draft',
+    ADD CONSTRAINT chk_posts_status CHECK (status IN ('draft', 'published', 'archived'));
+-- Public listings only ever read published posts that are not in the trash.
+CREATE INDEX IF NOT EXISTS idx_posts_published_created_at_id ON posts (created_at DESC, id DESC)
+    WHERE status = 'published' AND deleted_at IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_posts_scheduled_publish_at;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS chk_posts_publish_at;
+UPDATE posts SET status = 'draft' WHERE status = 'scheduled';
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS chk_posts_status,
+    ADD CONSTRAINT chk_posts_status CHECK (status IN ('draft', 'published', 'archived')),
+    DROP COLUMN IF EXISTS publish_at;
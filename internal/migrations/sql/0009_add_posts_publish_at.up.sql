@@ -0,0 +1,9 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS chk_posts_status,
+    ADD CONSTRAINT chk_posts_status CHECK (status IN ('draft', 'scheduled', 'published', 'archived')),
+    -- publish_at is set exactly while a post waits for the scheduler.
+    ADD CONSTRAINT chk_posts_publish_at CHECK ((status = 'scheduled') = (publish_at IS NOT NULL));
+-- The scheduler polls for due posts: WHERE status = 'scheduled' AND publish_at <= now().
+CREATE INDEX IF NOT EXISTS idx_posts_scheduled_publish_at ON posts (publish_at)
+    WHERE status = 'scheduled' AND deleted_at IS NULL;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id          BIGSERIAL PRIMARY KEY,
+    post_id     BIGINT      NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    -- Replies point at a top-level comment and go away with it.
+    parent_id   BIGINT      REFERENCES comments (id) ON DELETE CASCADE,
+    author_id   BIGINT      NOT NULL,
+    author_name TEXT        NOT NULL,
+    content     TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT chk_comments_content_length CHECK (char_length(content) BETWEEN 1 AND 2000)
+);
+-- Top-level listing with keyset pagination on (created_at, id).
+CREATE INDEX IF NOT EXISTS idx_comments_post_id_created_at ON comments (post_id, created_at, id)
+    WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id, created_at, id)
+    WHERE parent_id IS NOT NULL;
+-- Maintained by the comment repository alongside every insert and delete.
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+// Comment is a comment on a post. Threads are one level deep: ParentID is
+// set on replies and always points at a top-level comment.
+type Comment struct {
+	ID         int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID     int64     `json:"post_id" gorm:"not null"`
+	ParentID   *int64    `json:"parent_id"`
+	AuthorID   int64     `json:"author_id" gorm:"not null"`
+	AuthorName string    `json:"author_name" gorm:"not null"`
+	Content    string    `json:"content" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	Replies    []Comment `json:"replies,omitempty" gorm:"foreignKey:ParentID"`
+	// ReplyCount is the total number of replies, which may be more than
+	// were loaded into Replies.
+	ReplyCount int64 `json:"reply_count,omitempty" gorm:"-"`
+}
@@ -1,13 +1,39 @@
 package models
 
-import "time"
+import (
+	"time"
+	"gorm.io/gorm"
+)
+// Post lifecycle states. Only published posts appear in public listings.
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusArchived  = "archived"
+)
 type Post struct {
-	ID          int64     `json:"id" gorm:"primaryKey;autoIncrement"`
-	Title       string    `json:"title" gorm:"not null"`
-	Content     string    `json:"content" gorm:"not null"`
-	AuthorID    int64     `json:"author_id" gorm:"not null"`
-	AuthorName  string    `json:"author_name" gorm:"not null"`
-	AuthorEmail string    `json:"author_email" gorm:"not null"`
-	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID          int64      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Title       string     `json:"title" gorm:"not null"`
+	Content     string     `json:"content" gorm:"not null"`
+	AuthorID    int64      `json:"author_id" gorm:"not null"`
+	AuthorName  string     `json:"author_name" gorm:"not null"`
+	AuthorEmail string     `json:"author_email" gorm:"not null"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	Status      string     `json:"status" gorm:"not null;default:draft"`
+	PublishedAt *time.Time `json:"published_at"`
+	// PublishAt is when a scheduled post is due to be published.
+	PublishAt *time.Time `json:"publish_at"`
+	// Version is incremented by every update and exposed as the ETag.
+	Version int64 `json:"version" gorm:"not null;default:1"`
+	// DeletedAt marks a post as in the trash; GORM hides such rows from
+	// every query that is not explicitly Unscoped.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Tags      []Tag          `json:"tags" gorm:"many2many:post_tags"`
+	// CommentCount is kept up to date by the comment repository and is
+	// never written through the post itself.
+	CommentCount int64 `json:"comment_count" gorm:"->"`
+	// SearchVector is a generated column maintained by Postgres from title
+	// and content (see migrations) and is only ever used inside search queries.
+	SearchVector string `json:"-" gorm:"->:false;<-:false"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+// PostRevision is an immutable snapshot of a post's title and content as
+// they were before an edit. EditedBy and CreatedAt describe that edit.
+type PostRevision struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID    int64     `json:"post_id" gorm:"not null"`
+	Revision  int       `json:"revision" gorm:"not null"`
+	Title     string    `json:"title" gorm:"not null"`
+	Content   string    `json:"content" gorm:"not null"`
+	EditedBy  int64     `json:"edited_by" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+// Tag is identified by its normalized slug, e.g. "go-tips".
+type Tag struct {
+	ID        int64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+// PostTag is a row of the post_tags join table.
+type PostTag struct {
+	PostID int64 `gorm:"primaryKey"`
+	TagID  int64 `gorm:"primaryKey"`
+}
@@ -0,0 +1,127 @@
+package repository
+import (
+	"context"
+	"gorm.io/gorm"
+	"posts-api/internal/models"
+	"posts-api/pkg/utils"
+	"time"
+)
+// CommentRepository stores comments and keeps posts.comment_count in step
+// with them, in the same transaction.
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	GetComment(ctx context.Context, postID, id int64) (*models.Comment, error)
+	// GetComments returns up to limit top-level comments after the cursor,
+	// oldest first, each with its first replyLimit replies and its total
+	// ReplyCount.
+	GetComments(ctx context.Context, postID int64, cursor *utils.Cursor, limit, replyLimit int) ([]*models.Comment, error)
+	// GetReplies returns up to limit replies to a comment after the cursor,
+	// oldest first.
+	GetReplies(ctx context.Context, postID, parentID int64, cursor *utils.Cursor, limit int) ([]*models.Comment, error)
+	UpdateComment(ctx context.Context, comment *models.Comment) error
+	// DeleteComment removes the comment together with its replies.
+	DeleteComment(ctx context.Context, comment *models.Comment) error
+}
+type commentRepository struct {
+	db *gorm.DB
+}
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{
+		db: db,
+	}
+}
+func (r *commentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Replies").Create(comment).Error; err != nil {
+			return err
+		}
+		// Raw SQL leaves the post's version and updated_at alone; they
+		// describe edits to the post itself.
+		return tx.Exec("UPDATE posts SET comment_count = comment_count + 1 WHERE id = ?", comment.PostID).Error
+	})
+}
+func (r *commentRepository) GetComment(ctx context.Context, postID, id int64) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.db.WithContext(ctx).Where("post_id = ?", postID).First(&comment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+func (r *commentRepository) GetComments(ctx context.Context, postID int64, cursor *utils.Cursor, limit, replyLimit int) ([]*models.Comment, error) {
+	var comments []*models.Comment
+	query := r.db.WithContext(ctx).Where("post_id = ? AND parent_id IS NULL", postID)
+	if cursor != nil {
+		query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	err := query.Order("created_at ASC, id ASC").Limit(limit).Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return comments, nil
+	}
+	byID := make(map[int64]*models.Comment, len(comments))
+	parentIDs := make([]int64, len(comments))
+	for i, comment := range comments {
+		byID[comment.ID] = comment
+		parentIDs[i] = comment.ID
+	}
+	// A popular thread must not make the page unbounded, so only the first
+	// replyLimit replies of each comment are loaded; the rest are paged
+	// through GetReplies.
+	var rows []struct {
+		models.Comment
+		Total int64
+	}
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT comments.*,
+				ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY created_at, id) AS rn,
+				COUNT(*) OVER (PARTITION BY parent_id) AS total
+			FROM comments
+			WHERE parent_id IN ?
+		) replies
+		WHERE rn <= ?
+		ORDER BY parent_id, created_at, id`, parentIDs, replyLimit).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		parent := byID[*row.ParentID]
+		parent.Replies = append(parent.Replies, row.Comment)
+		parent.ReplyCount = row.Total
+	}
+	return comments, nil
+}
+func (r *commentRepository) GetReplies(ctx context.Context, postID, parentID int64, cursor *utils.Cursor, limit int) ([]*models.Comment, error) {
+	var replies []*models.Comment
+	query := r.db.WithContext(ctx).Where("post_id = ? AND parent_id = ?", postID, parentID)
+	if cursor != nil {
+		query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	err := query.Order("created_at ASC, id ASC").Limit(limit).Find(&replies).Error
+	if err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+func (r *commentRepository) UpdateComment(ctx context.Context, comment *models.Comment) error {
+	comment.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(comment).Updates(map[string]interface{}{
+		"content":    comment.Content,
+		"updated_at": comment.UpdatedAt,
+	}).Error
+}
+func (r *commentRepository) DeleteComment(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Comment{}, comment.ID).Error; err != nil {
+			return err
+		}
+		// Replies go with their parent through ON DELETE CASCADE, including
+		// ones inserted while this delete waited on the parent row, so the
+		// count is recomputed rather than adjusted by the rows seen here.
+		return tx.Exec("UPDATE posts SET comment_count = (SELECT COUNT(*) FROM comments WHERE post_id = ?) WHERE id = ?",
+			comment.PostID, comment.PostID).Error
+	})
+}
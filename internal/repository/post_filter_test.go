@@ -0,0 +1,94 @@
+package repository
+import (
+	"context"
+	"posts-api/internal/models"
+	"posts-api/internal/testutil"
+	"reflect"
+	"testing"
+	"time"
+)
+func TestPostFilterTags(t *testing.T) {
+	db := testutil.OpenDB(t)
+	ctx := context.Background()
+	repo := NewPostRepository(db)
+	for _, p := range []struct {
+		title string
+		tags  []string
+	}{
+		{"go only", []string{"go"}},
+		{"go and databases", []string{"go", "databases"}},
+		{"databases only", []string{"databases"}},
+		{"untagged", nil},
+	} {
+		now := time.Now()
+		post := &models.Post{
+			Title:       p.title,
+			Content:     "content",
+			AuthorID:    1,
+			AuthorName:  "author",
+			AuthorEmail: "author@example.com",
+			Status:      models.PostStatusPublished,
+			PublishedAt: &now,
+		}
+		for _, slug := range p.tags {
+			post.Tags = append(post.Tags, models.Tag{Slug: slug})
+		}
+		if err := repo.CreatePost(ctx, post); err != nil {
+			t.Fatalf("failed to create post: %v", err)
+		}
+	}
+	tests := []struct {
+		name   string
+		filter PostFilter
+		want   []string
+	}{
+		{
+			name:   "any of one tag",
+			filter: PostFilter{Tags: []string{"go"}},
+			want:   []string{"go and databases", "go only"},
+		},
+		{
+			name:   "any of two tags",
+			filter: PostFilter{Tags: []string{"go", "databases"}},
+			want:   []string{"databases only", "go and databases", "go only"},
+		},
+		{
+			name:   "all of two tags",
+			filter: PostFilter{Tags: []string{"go", "databases"}, MatchAllTags: true},
+			want:   []string{"go and databases"},
+		},
+		{
+			name:   "all including an unknown tag",
+			filter: PostFilter{Tags: []string{"go", "missing"}, MatchAllTags: true},
+			want:   []string{},
+		},
+		{
+			name:   "any of an unknown tag",
+			filter: PostFilter{Tags: []string{"missing"}},
+			want:   []string{},
+		},
+	}
+	sort := PostSort{Field: "title"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, err := repo.GetPostsPaginated(ctx, tt.filter, sort, 0, 10)
+			if err != nil {
+				t.Fatalf("failed to list posts: %v", err)
+			}
+			got := make([]string, len(posts))
+			for i, post := range posts {
+				got[i] = post.Title
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("posts = %q, want %q", got, tt.want)
+			}
+			total, err := repo.GetTotalPosts(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to count posts: %v", err)
+			}
+			if total != int64(len(tt.want)) {
+				t.Errorf("total = %d, want %d", total, len(tt.want))
+			}
+		})
+	}
+}
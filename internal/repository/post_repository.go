@@ -1,16 +1,62 @@
 package repository
 import (
+	"context"
+	"errors"
 	"posts-api/internal/models"
+	"posts-api/pkg/utils"
+	"time"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
+// ErrVersionConflict is returned by conditional writes when the stored post
+// no longer has the version the caller expected.
+var ErrVersionConflict = errors.New("post version conflict")
+type PostFilter struct {
+	AuthorIDs     []int64
+	Statuses      []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedSince  *time.Time
+	// Tags holds tag slugs; posts match when they carry any of them, or all
+	// of them with MatchAllTags.
+	Tags         []string
+	MatchAllTags bool
+}
+type PostSort struct {
+	Field string
+	Desc  bool
+}
+var sortableColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"title":      "title",
+}
+var DefaultPostSort = PostSort{Field: "created_at", Desc: true}
+type PostSearchResult struct {
+	models.Post    `gorm:"embedded"`
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
 type PostRepository interface {
-	CreatePost(post *models.Post) error
-	GetPostByID(id int64) (*models.Post, error)
-	GetAllPosts() ([]*models.Post, error)
-	UpdatePost(post *models.Post) error
-	DeletePost(id int64) error
-	GetByAuthorID(authorID int64) ([]*models.Post, error)
-	GetTotalPosts() (int64, error)
+	CreatePost(ctx context.Context, post *models.Post) error
+	GetPostByID(ctx context.Context, id int64) (*models.Post, error)
+	GetPostsPaginated(ctx context.Context, filter PostFilter, sort PostSort, offset, limit int) ([]*models.Post, error)
+	UpdatePost(ctx context.Context, post *models.Post, editorID int64) error
+	DeletePost(ctx context.Context, id int64, version int64) error
+	GetTotalPosts(ctx context.Context, filter PostFilter) (int64, error)
+	GetPostsByCursor(ctx context.Context, filter PostFilter, cursor *utils.Cursor, limit int) ([]*models.Post, error)
+	SearchPosts(ctx context.Context, query string, offset, limit int) ([]*PostSearchResult, error)
+	GetTotalSearchResults(ctx context.Context, query string) (int64, error)
+	GetTrashedPosts(ctx context.Context, authorID int64, offset, limit int) ([]*models.Post, error)
+	GetTotalTrashedPosts(ctx context.Context, authorID int64) (int64, error)
+	GetPostByIDWithTrashed(ctx context.Context, id int64) (*models.Post, error)
+	// RestorePost takes a trashed post out of the trash if it still has
+	// version, and returns ErrVersionConflict when it was restored, edited or
+	// purged in the meantime.
+	RestorePost(ctx context.Context, id int64, version int64) error
+	PurgeDeletedPosts(ctx context.Context, deletedBefore time.Time, batchSize int) (int64, error)
+	PublishDuePosts(ctx context.Context, now time.Time, batchSize int) (int64, error)
 }
 type postRepository struct {
 	db *gorm.DB
@@ -20,44 +66,394 @@ func NewPostRepository(db *gorm.DB) PostRepository {
 		db: db,
 	}
 }
-func (r *postRepository) CreatePost(post *models.Post) error {
-	return r.db.Create(post).Error
+func (r *postRepository) CreatePost(ctx context.Context, post *models.Post) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(post).Error; err != nil {
+			return err
+		}
+		return replaceTags(tx, post)
+	})
 }
-func (r *postRepository) GetPostByID(id int64) (*models.Post, error) {
+func (r *postRepository) GetPostByID(ctx context.Context, id int64) (*models.Post, error) {
 	var post models.Post
-	err := r.db.First(&post, id).Error
+	err := r.db.WithContext(ctx).Preload("Tags", orderTags).First(&post, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &post, nil
 }
-func (r *postRepository) GetAllPosts() ([]*models.Post, error) {
+func (r *postRepository) GetPostsPaginated(ctx context.Context, filter PostFilter, sort PostSort, offset, limit int) ([]*models.Post, error) {
 	var posts []*models.Post
-	err := r.db.Find(&posts).Error
+	column, ok := sortableColumns[sort.Field]
+	if !ok {
+		column = DefaultPostSort.Field
+	}
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+	err := r.applyFilter(r.db.WithContext(ctx).Preload("Tags", orderTags), filter).
+		Order(column + " " + direction + ", id " + direction).
+		Offset(offset).
+		Limit(limit).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+// DeletePost moves the post to the trash if it still has the given version.
+func (r *postRepository) DeletePost(ctx context.Context, id int64, version int64) error {
+	result := r.db.WithContext(ctx).Where("version = ?", version).Delete(&models.Post{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+// UpdatePost writes post's editable fields if the stored row still has
+// post.Version, and bumps the version. When title or content changed, the
+// previous values are recorded as the post's next revision in the same
+// transaction.
+func (r *postRepository) UpdatePost(ctx context.Context, post *models.Post, editorID int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previous models.Post
+		// Locking the row serialises concurrent edits, which keeps the
+		// MAX(revision)+1 numbering below free of duplicates.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "title", "content", "version").
+			First(&previous, post.ID).Error
+		if err != nil {
+			return err
+		}
+		if previous.Version != post.Version {
+			return ErrVersionConflict
+		}
+		if previous.Title != post.Title || previous.Content != post.Content {
+			var last int
+			err := tx.Model(&models.PostRevision{}).
+				Select("COALESCE(MAX(revision), 0)").
+				Where("post_id = ?", post.ID).
+				Scan(&last).Error
+			if err != nil {
+				return err
+			}
+			revision := &models.PostRevision{
+				PostID:   post.ID,
+				Revision: last + 1,
+				Title:    previous.Title,
+				Content:  previous.Content,
+				EditedBy: editorID,
+			}
+			if err := tx.Create(revision).Error; err != nil {
+				return err
+			}
+		}
+		updatedAt := time.Now()
+		result := tx.Model(&models.Post{}).
+			Where("id = ? AND version = ?", post.ID, post.Version).
+			Updates(map[string]interface{}{
+				"title":        post.Title,
+				"content":      post.Content,
+				"status":       post.Status,
+				"published_at": post.PublishedAt,
+				"publish_at":   post.PublishAt,
+				"updated_at":   updatedAt,
+				"version":      gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		post.UpdatedAt = updatedAt
+		post.Version++
+		return replaceTags(tx, post)
+	})
+}
+func (r *postRepository) GetTotalPosts(ctx context.Context, filter PostFilter) (int64, error) {
+	var count int64
+	err := r.applyFilter(r.db.WithContext(ctx).Model(&models.Post{}), filter).Count(&count).Error
 	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+// GetPostsByCursor returns up to limit posts on the cursor's side of the
+// (created_at, id) keyset, always in newest-first order.
+func (r *postRepository) GetPostsByCursor(ctx context.Context, filter PostFilter, cursor *utils.Cursor, limit int) ([]*models.Post, error) {
+	var posts []*models.Post
+	query := r.applyFilter(r.db.WithContext(ctx).Preload("Tags", orderTags), filter)
+	backwards := cursor != nil && cursor.Direction == utils.CursorPrev
+	switch {
+	case cursor == nil:
+		query = query.Order("created_at DESC, id DESC")
+	case backwards:
+		query = query.Where("(created_at, id) > (?, ?)", cursor.CreatedAt, cursor.ID).
+			Order("created_at ASC, id ASC")
+	default:
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID).
+			Order("created_at DESC, id DESC")
+	}
+	if err := query.Limit(limit).Find(&posts).Error; err != nil {
 		return nil, err
 	}
+	if backwards {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+		}
+	}
 	return posts, nil
 }
-func (r *postRepository) DeletePost(id int64) error {
-	return r.db.Delete(&models.Post{}, id).Error
+const searchQuery = "websearch_to_tsquery('english', ?)"
+// htmlEscaped wraps column in SQL that HTML-escapes it, so the <mark> tags
+// ts_headline adds are the only markup in highlights. The text search parser
+// reads entities as single tokens, so they are never split or highlighted.
+func htmlEscaped(column string) string {
+	return "replace(replace(replace(replace(replace(" + column +
+		", '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&quot;'), '''', '&#39;')"
+}
+// SearchPosts only matches published posts; search is a public listing.
+func (r *postRepository) SearchPosts(ctx context.Context, query string, offset, limit int) ([]*PostSearchResult, error) {
+	var results []*PostSearchResult
+	err := r.db.WithContext(ctx).Table("posts, "+searchQuery+" AS query", query).
+		Select("posts.id, posts.title, posts.content, posts.author_id, posts.author_name, posts.author_email, " +
+			"posts.created_at, posts.updated_at, posts.status, posts.published_at, posts.publish_at, posts.version, posts.comment_count, " +
+			"ts_rank(posts.search_vector, query) AS rank, " +
+			"ts_headline('english', " + htmlEscaped("posts.title") + ", query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>') AS title_highlight, " +
+			"ts_headline('english', " + htmlEscaped("posts.content") + ", query, 'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=<mark>, StopSel=</mark>') AS snippet").
+		Where("posts.search_vector @@ query AND posts.status = ? AND posts.deleted_at IS NULL", models.PostStatusPublished).
+		Order("rank DESC, posts.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]*models.Post, len(results))
+	for i := range results {
+		posts[i] = &results[i].Post
+	}
+	if err := r.loadTags(ctx, posts); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
-func (r *postRepository) UpdatePost(post *models.Post) error {
-	return r.db.Save(post).Error
+func (r *postRepository) GetTotalSearchResults(ctx context.Context, query string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Post{}).
+		Where("search_vector @@ "+searchQuery+" AND status = ?", query, models.PostStatusPublished).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
 }
-func (r *postRepository) GetByAuthorID(authorID int64) ([]*models.Post, error) {
+func (r *postRepository) GetTrashedPosts(ctx context.Context, authorID int64, offset, limit int) ([]*models.Post, error) {
 	var posts []*models.Post
-	err := r.db.Where("author_id = ?", authorID).Find(&posts).Error
+	err := r.db.WithContext(ctx).Unscoped().Preload("Tags", orderTags).
+		Where("author_id = ? AND deleted_at IS NOT NULL", authorID).
+		Order("deleted_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&posts).Error
 	if err != nil {
 		return nil, err
 	}
 	return posts, nil
 }
-func (r *postRepository) GetTotalPosts() (int64, error) {
+func (r *postRepository) GetTotalTrashedPosts(ctx context.Context, authorID int64) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Post{}).Count(&count).Error
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Post{}).
+		Where("author_id = ? AND deleted_at IS NOT NULL", authorID).
+		Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+// GetPostByIDWithTrashed looks a post up whether or not it is in the trash.
+func (r *postRepository) GetPostByIDWithTrashed(ctx context.Context, id int64) (*models.Post, error) {
+	var post models.Post
+	err := r.db.WithContext(ctx).Unscoped().Preload("Tags", orderTags).First(&post, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+func (r *postRepository) RestorePost(ctx context.Context, id int64, version int64) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&models.Post{}).
+		Where("id = ? AND version = ? AND deleted_at IS NOT NULL", id, version).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+// PurgeDeletedPosts permanently removes posts trashed before deletedBefore,
+// batchSize rows per statement to keep locks short, and returns how many
+// rows were removed.
+func (r *postRepository) PurgeDeletedPosts(ctx context.Context, deletedBefore time.Time, batchSize int) (int64, error) {
+	var purged int64
+	for {
+		batch := r.db.Unscoped().Model(&models.Post{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Limit(batchSize)
+		result := r.db.WithContext(ctx).Unscoped().Where("id IN (?)", batch).Delete(&models.Post{})
+		if result.Error != nil {
+			return purged, result.Error
+		}
+		purged += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			return purged, nil
+		}
+	}
+}
+// PublishDuePosts publishes scheduled posts whose publish_at has passed,
+// batchSize rows per transaction, and returns how many were published. Rows
+// are claimed with SKIP LOCKED so concurrent replicas work on disjoint
+// batches instead of waiting on or double-publishing each other's.
+func (r *postRepository) PublishDuePosts(ctx context.Context, now time.Time, batchSize int) (int64, error) {
+	var published int64
+	for {
+		var batch int64
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var ids []int64
+			err := tx.Model(&models.Post{}).
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, now).
+				Order("publish_at, id").
+				Limit(batchSize).
+				Pluck("id", &ids).Error
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+			result := tx.Model(&models.Post{}).
+				Where("id IN ?", ids).
+				Updates(map[string]interface{}{
+					"status":       models.PostStatusPublished,
+					"published_at": gorm.Expr("publish_at"),
+					"publish_at":   nil,
+					"updated_at":   now,
+					"version":      gorm.Expr("version + 1"),
+				})
+			batch = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return published, err
+		}
+		published += batch
+		if batch < int64(batchSize) {
+			return published, nil
+		}
+	}
+}
+func (r *postRepository) applyFilter(query *gorm.DB, filter PostFilter) *gorm.DB {
+	if len(filter.AuthorIDs) == 1 {
+		query = query.Where("author_id = ?", filter.AuthorIDs[0])
+	} else if len(filter.AuthorIDs) > 1 {
+		query = query.Where("author_id IN ?", filter.AuthorIDs)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at > ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UpdatedSince != nil {
+		query = query.Where("updated_at >= ?", *filter.UpdatedSince)
+	}
+	if len(filter.Tags) > 0 {
+		tagged := r.db.Table("post_tags").
+			Select("post_tags.post_id").
+			Joins("JOIN tags ON tags.id = post_tags.tag_id").
+			Where("tags.slug IN ?", filter.Tags)
+		if filter.MatchAllTags {
+			tagged = tagged.Group("post_tags.post_id").Having("COUNT(*) = ?", len(filter.Tags))
+		}
+		query = query.Where("posts.id IN (?)", tagged)
+	}
+	return query
+}
+func orderTags(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.slug")
+}
+// replaceTags makes post.Tags, matched by slug, the post's complete tag set,
+// creating tags that don't exist yet.
+func replaceTags(tx *gorm.DB, post *models.Post) error {
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostTag{}).Error; err != nil {
+		return err
+	}
+	if len(post.Tags) == 0 {
+		post.Tags = []models.Tag{}
+		return nil
+	}
+	slugs := make([]string, len(post.Tags))
+	newTags := make([]models.Tag, len(post.Tags))
+	for i, tag := range post.Tags {
+		slugs[i] = tag.Slug
+		newTags[i] = models.Tag{Slug: tag.Slug}
+	}
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).
+		Create(&newTags).Error
+	if err != nil {
+		return err
+	}
+	var tags []models.Tag
+	if err := tx.Where("slug IN ?", slugs).Order("slug").Find(&tags).Error; err != nil {
+		return err
+	}
+	postTags := make([]models.PostTag, len(tags))
+	for i, tag := range tags {
+		postTags[i] = models.PostTag{PostID: post.ID, TagID: tag.ID}
+	}
+	if err := tx.Create(&postTags).Error; err != nil {
+		return err
+	}
+	post.Tags = tags
+	return nil
+}
+// loadTags fills in Tags for posts that were not loaded with Preload.
+func (r *postRepository) loadTags(ctx context.Context, posts []*models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*models.Post, len(posts))
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		post.Tags = []models.Tag{}
+		byID[post.ID] = post
+		ids[i] = post.ID
+	}
+	var rows []struct {
+		PostID int64
+		models.Tag
+	}
+	err := r.db.WithContext(ctx).Table("post_tags").
+		Select("post_tags.post_id, tags.*").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ?", ids).
+		Order("tags.slug").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		byID[row.PostID].Tags = append(byID[row.PostID].Tags, row.Tag)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+import (
+	"context"
+	"posts-api/internal/models"
+	"posts-api/internal/testutil"
+	"posts-api/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+	"gorm.io/gorm"
+)
+// explain returns the plan for sql with sequential scans disabled, so the
+// planner shows which index it prefers even on a small test table.
+func explain(t *testing.T, db *gorm.DB, sql string) string {
+	t.Helper()
+	var plan []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL enable_seqscan = off").Error; err != nil {
+			return err
+		}
+		return tx.Raw("EXPLAIN " + sql).Scan(&plan).Error
+	})
+	if err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	return strings.Join(plan, "\n")
+}
+func TestPostListingsUseIndexes(t *testing.T) {
+	db := testutil.OpenDB(t)
+	ctx := context.Background()
+	// Enough rows across authors and statuses for the planner's estimates
+	// to tell the indexes apart.
+	err := db.Exec(`
+		INSERT INTO posts (title, content, author_id, author_name, author_email, status, created_at, updated_at)
+		SELECT 'Post ' || n, 'Content', n % 50 + 1, 'author', 'author@example.com',
+			CASE WHEN n % 4 = 0 THEN 'draft' ELSE 'published' END,
+			now() - n * interval '1 minute', now()
+		FROM generate_series(1, 5000) AS n`).Error
+	if err != nil {
+		t.Fatalf("failed to seed posts: %v", err)
+	}
+	if err := db.Exec("ANALYZE posts").Error; err != nil {
+		t.Fatalf("failed to analyze posts: %v", err)
+	}
+	published := []string{models.PostStatusPublished}
+	tests := []struct {
+		name  string
+		index string
+		query func(repo PostRepository) error
+	}{
+		{
+			name:  "posts by author",
+			index: "idx_posts_author_id_created_at",
+			query: func(repo PostRepository) error {
+				filter := PostFilter{AuthorIDs: []int64{1}, Statuses: published}
+				_, err := repo.GetPostsPaginated(ctx, filter, DefaultPostSort, 0, 10)
+				return err
+			},
+		},
+		{
+			name:  "keyset listing",
+			index: "idx_posts_published_created_at_id",
+			query: func(repo PostRepository) error {
+				cursor := &utils.Cursor{CreatedAt: time.Now(), ID: 100, Direction: utils.CursorNext}
+				_, err := repo.GetPostsByCursor(ctx, PostFilter{Statuses: published}, cursor, 11)
+				return err
+			},
+		},
+		{
+			name:  "keyset listing backwards",
+			index: "idx_posts_published_created_at_id",
+			query: func(repo PostRepository) error {
+				cursor := &utils.Cursor{CreatedAt: time.Now(), ID: 100, Direction: utils.CursorPrev}
+				_, err := repo.GetPostsByCursor(ctx, PostFilter{Statuses: published}, cursor, 11)
+				return err
+			},
+		},
+		{
+			name:  "keyset listing of every status",
+			index: "idx_posts_created_at_id",
+			query: func(repo PostRepository) error {
+				cursor := &utils.Cursor{CreatedAt: time.Now(), ID: 100, Direction: utils.CursorNext}
+				_, err := repo.GetPostsByCursor(ctx, PostFilter{}, cursor, 11)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newSQLRecorder()
+			repo := NewPostRepository(db.Session(&gorm.Session{Logger: recorder}))
+			if err := tt.query(repo); err != nil {
+				t.Fatalf("query failed: %v", err)
+			}
+			plan := explain(t, db, recorder.find(t, `SELECT * FROM "posts"`))
+			if !strings.Contains(plan, tt.index) {
+				t.Errorf("query does not use %s:\n%s", tt.index, plan)
+			}
+		})
+	}
+}
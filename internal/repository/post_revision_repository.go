@@ -0,0 +1,50 @@
+package repository
+import (
+	"context"
+	"gorm.io/gorm"
+	"posts-api/internal/models"
+)
+// PostRevisionRepository reads revision history. Revisions are written by
+// PostRepository.UpdatePost, in the same transaction as the edit.
+type PostRevisionRepository interface {
+	GetRevisions(ctx context.Context, postID int64, offset, limit int) ([]*models.PostRevision, error)
+	GetTotalRevisions(ctx context.Context, postID int64) (int64, error)
+	GetRevision(ctx context.Context, postID int64, revision int) (*models.PostRevision, error)
+}
+type postRevisionRepository struct {
+	db *gorm.DB
+}
+func NewPostRevisionRepository(db *gorm.DB) PostRevisionRepository {
+	return &postRevisionRepository{
+		db: db,
+	}
+}
+func (r *postRevisionRepository) GetRevisions(ctx context.Context, postID int64, offset, limit int) ([]*models.PostRevision, error) {
+	var revisions []*models.PostRevision
+	err := r.db.WithContext(ctx).
+		Where("post_id = ?", postID).
+		Order("revision DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+func (r *postRevisionRepository) GetTotalRevisions(ctx context.Context, postID int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.PostRevision{}).Where("post_id = ?", postID).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+func (r *postRevisionRepository) GetRevision(ctx context.Context, postID int64, revision int) (*models.PostRevision, error) {
+	var rev models.PostRevision
+	err := r.db.WithContext(ctx).Where("post_id = ? AND revision = ?", postID, revision).First(&rev).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
@@ -0,0 +1,46 @@
+package repository
+import (
+	"context"
+	"posts-api/internal/models"
+	"posts-api/internal/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+func TestSearchPostsEscapesHighlights(t *testing.T) {
+	db := testutil.OpenDB(t)
+	ctx := context.Background()
+	repo := NewPostRepository(db)
+	now := time.Now()
+	post := &models.Post{
+		Title:       `Exploits <script>alert("x")</script> & more`,
+		Content:     `Exploits are fun: <img src=x onerror='alert(1)'> exploits everywhere`,
+		AuthorID:    1,
+		AuthorName:  "author",
+		AuthorEmail: "author@example.com",
+		Status:      models.PostStatusPublished,
+		PublishedAt: &now,
+	}
+	if err := repo.CreatePost(ctx, post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	results, err := repo.SearchPosts(ctx, "exploits", 0, 10)
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	for name, highlight := range map[string]string{"title": results[0].TitleHighlight, "snippet": results[0].Snippet} {
+		withoutMarks := strings.NewReplacer("<mark>", "", "</mark>", "").Replace(highlight)
+		if strings.ContainsAny(withoutMarks, `<>"'`) {
+			t.Errorf("%s highlight %q contains unescaped markup", name, highlight)
+		}
+		if !strings.Contains(highlight, "<mark>Exploits</mark>") {
+			t.Errorf("%s highlight %q does not mark the match", name, highlight)
+		}
+	}
+	if !strings.Contains(results[0].TitleHighlight, "&lt;script&gt;") {
+		t.Errorf("title highlight %q lost the escaped text", results[0].TitleHighlight)
+	}
+}
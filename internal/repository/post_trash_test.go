@@ -0,0 +1,51 @@
+package repository
+import (
+	"context"
+	"errors"
+	"posts-api/internal/models"
+	"posts-api/internal/testutil"
+	"testing"
+	"time"
+)
+func TestRestorePostIsConditional(t *testing.T) {
+	db := testutil.OpenDB(t)
+	ctx := context.Background()
+	repo := NewPostRepository(db)
+	now := time.Now()
+	post := &models.Post{
+		Title:       "Trashed",
+		Content:     "content",
+		AuthorID:    1,
+		AuthorName:  "author",
+		AuthorEmail: "author@example.com",
+		Status:      models.PostStatusPublished,
+		PublishedAt: &now,
+	}
+	if err := repo.CreatePost(ctx, post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	if err := repo.DeletePost(ctx, post.ID, post.Version); err != nil {
+		t.Fatalf("failed to trash post: %v", err)
+	}
+	trashed, err := repo.GetPostByIDWithTrashed(ctx, post.ID)
+	if err != nil {
+		t.Fatalf("failed to get trashed post: %v", err)
+	}
+	if err := repo.RestorePost(ctx, post.ID, trashed.Version); err != nil {
+		t.Fatalf("failed to restore post: %v", err)
+	}
+	// A second restore based on the same read lost the race.
+	if err := repo.RestorePost(ctx, post.ID, trashed.Version); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("second restore = %v, want ErrVersionConflict", err)
+	}
+	if err := repo.RestorePost(ctx, post.ID+1, 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("restore of a missing post = %v, want ErrVersionConflict", err)
+	}
+	restored, err := repo.GetPostByID(ctx, post.ID)
+	if err != nil {
+		t.Fatalf("restored post is not visible: %v", err)
+	}
+	if restored.Version != trashed.Version+1 {
+		t.Errorf("version = %d, want %d", restored.Version, trashed.Version+1)
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"gorm.io/gorm/logger"
+)
+// sqlRecorder is a GORM logger that keeps every statement it is given, with
+// the bound values interpolated.
+type sqlRecorder struct {
+	logger.Interface
+	mu         sync.Mutex
+	statements []string
+}
+func newSQLRecorder() *sqlRecorder {
+	return &sqlRecorder{Interface: logger.Discard}
+}
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, sql)
+}
+// find returns the first recorded statement starting with prefix.
+func (r *sqlRecorder) find(t *testing.T, prefix string) string {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sql := range r.statements {
+		if strings.HasPrefix(sql, prefix) {
+			return sql
+		}
+	}
+	t.Fatalf("no statement starting with %q in %q", prefix, r.statements)
+	return ""
+}
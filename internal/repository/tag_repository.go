@@ -0,0 +1,38 @@
+package repository
+import (
+	"context"
+	"posts-api/internal/models"
+	"gorm.io/gorm"
+)
+type TagUsage struct {
+	Slug      string
+	PostCount int64
+}
+type TagRepository interface {
+	// GetTagUsage returns tags used by at least one published post that is
+	// not in the trash, most used first.
+	GetTagUsage(ctx context.Context, limit int) ([]*TagUsage, error)
+}
+type tagRepository struct {
+	db *gorm.DB
+}
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{
+		db: db,
+	}
+}
+func (r *tagRepository) GetTagUsage(ctx context.Context, limit int) ([]*TagUsage, error) {
+	var usage []*TagUsage
+	err := r.db.WithContext(ctx).Table("tags").
+		Select("tags.slug, COUNT(*) AS post_count").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.status = ? AND posts.deleted_at IS NULL", models.PostStatusPublished).
+		Group("tags.id, tags.slug").
+		Order("post_count DESC, tags.slug").
+		Limit(limit).
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -2,38 +2,73 @@ package routes
 import (
 	"net/http"
 	"posts-api/internal/handlers"
+	"posts-api/internal/metrics"
 	"posts-api/internal/middleware"
 	"posts-api/internal/services"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
-func SetupRoutes(postHandler *handlers.PostHandler, userService services.UserService) http.Handler {
+func SetupRoutes(postHandler *handlers.PostHandler, revisionHandler *handlers.RevisionHandler, tagHandler *handlers.TagHandler, commentHandler *handlers.CommentHandler, healthHandler *handlers.HealthHandler, userService services.UserService) http.Handler {
 	router := mux.NewRouter()
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status": "healthy", "service": "posts-api"}`))
-	}).Methods("GET")
+	router.Use(middleware.MetricsMiddleware)
+	router.Use(middleware.TracingRouteMiddleware)
+	// mux skips router middleware when no route matches, so 404s and 405s
+	// are counted by wrapping their handlers instead.
+	router.NotFoundHandler = middleware.MetricsMiddleware(http.NotFoundHandler())
+	router.MethodNotAllowedHandler = middleware.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+	router.HandleFunc("/health", healthHandler.Health).Methods("GET")
+	router.HandleFunc("/health/live", healthHandler.Live).Methods("GET")
+	router.HandleFunc("/health/ready", healthHandler.Ready).Methods("GET")
 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"message": "Posts API is running!", "version": "1.0.0"}`))
 	}).Methods("GET")
 	public := router.PathPrefix("/api/v1").Subrouter()
+	public.Use(middleware.OptionalJWTMiddleware(userService))
 	
 	public.HandleFunc("/posts", postHandler.GetAllPosts).Methods("GET")
 	
+	public.HandleFunc("/posts/search", postHandler.SearchPosts).Methods("GET")
+	
 	public.HandleFunc("/posts/{id:[0-9]+}", postHandler.GetPost).Methods("GET")
 	
 	public.HandleFunc("/posts/author/{authorId:[0-9]+}", postHandler.GetPostsByAuthor).Methods("GET")
+	
+	public.HandleFunc("/tags", tagHandler.GetTags).Methods("GET")
+	public.HandleFunc("/posts/{id:[0-9]+}/comments", commentHandler.GetComments).Methods("GET")
+	public.HandleFunc("/posts/{id:[0-9]+}/comments/{commentId:[0-9]+}/replies", commentHandler.GetReplies).Methods("GET")
 	protected := router.PathPrefix("/api/v1").Subrouter()
 	protected.Use(middleware.JWTMiddleware(userService))
 	
 	protected.HandleFunc("/posts", postHandler.CreatePost).Methods("POST")
 	
 	protected.HandleFunc("/posts/{id:[0-9]+}", postHandler.UpdatePost).Methods("PUT")
+	protected.HandleFunc("/posts/{id:[0-9]+}", postHandler.PatchPost).Methods("PATCH")
 	protected.HandleFunc("/posts/{id:[0-9]+}", postHandler.DeletePost).Methods("DELETE")
+	protected.HandleFunc("/posts/{id:[0-9]+}/restore", postHandler.RestorePost).Methods("POST")
+	protected.HandleFunc("/posts/{id:[0-9]+}/publish", postHandler.PublishPost).Methods("POST")
+	protected.HandleFunc("/posts/{id:[0-9]+}/unpublish", postHandler.UnpublishPost).Methods("POST")
+	protected.HandleFunc("/posts/{id:[0-9]+}/archive", postHandler.ArchivePost).Methods("POST")
+	protected.HandleFunc("/me/trash", postHandler.GetTrash).Methods("GET")
+	protected.HandleFunc("/me/drafts", postHandler.GetDrafts).Methods("GET")
+	protected.HandleFunc("/posts/{id:[0-9]+}/revisions", revisionHandler.GetRevisions).Methods("GET")
+	protected.HandleFunc("/posts/{id:[0-9]+}/revisions/diff", revisionHandler.DiffRevisions).Methods("GET")
+	protected.HandleFunc("/posts/{id:[0-9]+}/revisions/{rev:[0-9]+}", revisionHandler.GetRevision).Methods("GET")
+	protected.HandleFunc("/posts/{id:[0-9]+}/revisions/{rev:[0-9]+}/restore", revisionHandler.RestoreRevision).Methods("POST")
+	protected.HandleFunc("/posts/{id:[0-9]+}/comments", commentHandler.CreateComment).Methods("POST")
+	protected.HandleFunc("/posts/{id:[0-9]+}/comments/{commentId:[0-9]+}", commentHandler.UpdateComment).Methods("PUT")
+	protected.HandleFunc("/posts/{id:[0-9]+}/comments/{commentId:[0-9]+}", commentHandler.DeleteComment).Methods("DELETE")
 	corsConfig := middleware.DefaultCORSConfig()
 	corsMiddleware := middleware.NewCORSMiddleware(corsConfig)
 	
-	return corsMiddleware(router)
+	handler := middleware.RequestIDMiddleware(corsMiddleware(router))
+	return otelhttp.NewHandler(handler, "http.server",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return r.URL.Path != "/metrics" && r.URL.Path != "/health/live" && r.URL.Path != "/health/ready"
+		}),
+	)
 }
@@ -0,0 +1,59 @@
+package routes
+import (
+	"net/http"
+	"net/http/httptest"
+	"posts-api/internal/handlers"
+	"strconv"
+	"testing"
+	"github.com/prometheus/client_golang/prometheus"
+)
+// requestCount reads posts_api_http_requests_total for one label set.
+func requestCount(t *testing.T, method, route, status string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "posts_api_http_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["method"] == method && labels["route"] == route && labels["status"] == status {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+func TestUnmatchedRequestsAreCounted(t *testing.T) {
+	router := SetupRoutes(&handlers.PostHandler{}, &handlers.RevisionHandler{}, &handlers.TagHandler{}, &handlers.CommentHandler{}, &handlers.HealthHandler{}, nil)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{name: "unknown path", method: http.MethodGet, path: "/nope", status: http.StatusNotFound},
+		{name: "unknown API path", method: http.MethodGet, path: "/api/v1/nope", status: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPatch, path: "/health/live", status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := strconv.Itoa(tt.status)
+			before := requestCount(t, tt.method, "unmatched", code)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := requestCount(t, tt.method, "unmatched", code) - before; got != 1 {
+				t.Errorf("unmatched %s requests counted %v times, want 1", code, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package services
+import (
+	"fmt"
+	"posts-api/internal/models"
+	"posts-api/pkg/utils"
+	"strings"
+)
+type Action string
+const (
+	ActionViewPost    Action = "view"
+	ActionCreatePost  Action = "create"
+	ActionUpdatePost  Action = "update"
+	ActionDeletePost  Action = "delete"
+	ActionRestorePost Action = "restore"
+	ActionPublishPost Action = "publish"
+	// ActionModerateComments covers editing and deleting other people's
+	// comments on a post.
+	ActionModerateComments Action = "moderate_comments"
+)
+// actionDescriptions phrase each action for error messages.
+var actionDescriptions = map[Action]string{
+	ActionViewPost:         "view post",
+	ActionCreatePost:       "create post",
+	ActionUpdatePost:       "update post",
+	ActionDeletePost:       "delete post",
+	ActionRestorePost:      "restore post",
+	ActionPublishPost:      "change post status",
+	ActionModerateComments: "moderate comments",
+}
+func (a Action) describe() string {
+	if description, ok := actionDescriptions[a]; ok {
+		return description
+	}
+	return string(a)
+}
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+var ErrForbidden = &Error{Kind: utils.ErrorKindForbidden, Message: "forbidden"}
+// ForbiddenError is returned when the authorizer denies an action.
+type ForbiddenError struct {
+	Action Action
+	Reason string
+}
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden: cannot %s: %s", e.Action.describe(), e.Reason)
+}
+func (e *ForbiddenError) ErrorKind() utils.ErrorKind {
+	return utils.ErrorKindForbidden
+}
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+// Actor is the authenticated user an authorization decision is made for.
+type Actor struct {
+	ID   int64
+	Role string
+}
+func NewActor(user *UserDTO) Actor {
+	return Actor{ID: user.ID, Role: user.Role}
+}
+func (a Actor) hasRole(roles ...string) bool {
+	for _, role := range roles {
+		if strings.EqualFold(a.Role, role) {
+			return true
+		}
+	}
+	return false
+}
+type Authorizer interface {
+	Authorize(actor Actor, action Action, post *models.Post) error
+}
+type policy func(actor Actor, post *models.Post) (bool, string)
+type authorizer struct {
+	policies map[Action]policy
+}
+func NewAuthorizer() Authorizer {
+	return &authorizer{
+		policies: map[Action]policy{
+			// Unpublished posts are only visible to their author and moderators.
+			ActionViewPost: func(actor Actor, post *models.Post) (bool, string) {
+				if post != nil && post.Status == models.PostStatusPublished {
+					return true, ""
+				}
+				return authorOrModerator("post is not published")(actor, post)
+			},
+			ActionCreatePost: func(actor Actor, post *models.Post) (bool, string) {
+				return actor.ID > 0, "authentication required"
+			},
+			ActionUpdatePost:       authorOrModerator("only the author or a moderator can update this post"),
+			ActionDeletePost:       authorOrModerator("only the author or a moderator can delete this post"),
+			ActionRestorePost:      authorOrModerator("only the author or a moderator can restore this post"),
+			ActionPublishPost:      authorOrModerator("only the author or a moderator can change this post's status"),
+			ActionModerateComments: authorOrModerator("only the comment author, the post author or a moderator can change this comment"),
+		},
+	}
+}
+func authorOrModerator(reason string) policy {
+	return func(actor Actor, post *models.Post) (bool, string) {
+		if actor.hasRole(RoleAdmin, RoleModerator) {
+			return true, ""
+		}
+		return post != nil && actor.ID > 0 && post.AuthorID == actor.ID, reason
+	}
+}
+func (a *authorizer) Authorize(actor Actor, action Action, post *models.Post) error {
+	check, ok := a.policies[action]
+	if !ok {
+		return &ForbiddenError{Action: action, Reason: "no policy defined"}
+	}
+	if allowed, reason := check(actor, post); !allowed {
+		return &ForbiddenError{Action: action, Reason: reason}
+	}
+	return nil
+}
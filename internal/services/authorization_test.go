@@ -0,0 +1,44 @@
+package services
+import (
+	"errors"
+	"posts-api/internal/models"
+	"testing"
+)
+func TestAuthorizeDeniesWithActionSpecificMessage(t *testing.T) {
+	authorizer := NewAuthorizer()
+	post := &models.Post{AuthorID: 1, Status: models.PostStatusDraft}
+	stranger := Actor{ID: 2, Role: RoleUser}
+	tests := []struct {
+		action Action
+		want   string
+	}{
+		{ActionViewPost, "forbidden: cannot view post: post is not published"},
+		{ActionPublishPost, "forbidden: cannot change post status: only the author or a moderator can change this post's status"},
+		{ActionModerateComments, "forbidden: cannot moderate comments: only the comment author, the post author or a moderator can change this comment"},
+		{Action("unknown"), "forbidden: cannot unknown: no policy defined"},
+	}
+	for _, tt := range tests {
+		err := authorizer.Authorize(stranger, tt.action, post)
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("Authorize(%s) = %v, want ErrForbidden", tt.action, err)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("Authorize(%s) message = %q, want %q", tt.action, err.Error(), tt.want)
+		}
+	}
+}
+func TestAuthorizeAllowsAuthorsAndModerators(t *testing.T) {
+	authorizer := NewAuthorizer()
+	post := &models.Post{AuthorID: 1, Status: models.PostStatusDraft}
+	for _, actor := range []Actor{{ID: 1, Role: RoleUser}, {ID: 3, Role: RoleModerator}, {ID: 4, Role: "Admin"}} {
+		for _, action := range []Action{ActionViewPost, ActionUpdatePost, ActionDeletePost, ActionPublishPost, ActionModerateComments} {
+			if err := authorizer.Authorize(actor, action, post); err != nil {
+				t.Errorf("Authorize(%+v, %s) = %v, want allowed", actor, action, err)
+			}
+		}
+	}
+	if err := authorizer.Authorize(Actor{}, ActionUpdatePost, &models.Post{}); err == nil {
+		t.Error("anonymous actor allowed to update an authorless post")
+	}
+}
@@ -0,0 +1,106 @@
+package services
+import (
+	"testing"
+	"time"
+)
+func newTestBreaker(threshold int, cooldown time.Duration) (*circuitBreaker, *fakeClock) {
+	clock := newFakeClock()
+	b := newCircuitBreaker(threshold, cooldown)
+	b.now = clock.now
+	return b, clock
+}
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Minute)
+	for i := 0; i < 2; i++ {
+		if !b.allow() {
+			t.Fatalf("call %d rejected before the threshold", i)
+		}
+		b.failure()
+	}
+	if b.state != breakerClosed {
+		t.Fatalf("state = %v after 2 failures, want closed", b.state)
+	}
+	b.allow()
+	b.failure()
+	if b.state != breakerOpen || b.allow() {
+		t.Errorf("breaker should be open and rejecting after 3 failures")
+	}
+}
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+	b.failure()
+	b.success()
+	b.failure()
+	if b.state != breakerClosed {
+		t.Errorf("state = %v, want closed since failures were not consecutive", b.state)
+	}
+}
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	tests := []struct {
+		name      string
+		outcome   func(b *circuitBreaker)
+		wantState breakerState
+		wantAllow bool
+	}{
+		{
+			name:      "successful probe closes",
+			outcome:   (*circuitBreaker).success,
+			wantState: breakerClosed,
+			wantAllow: true,
+		},
+		{
+			name:      "failed probe reopens",
+			outcome:   (*circuitBreaker).failure,
+			wantState: breakerOpen,
+			wantAllow: false,
+		},
+		{
+			name:      "released probe lets another through",
+			outcome:   (*circuitBreaker).release,
+			wantState: breakerHalfOpen,
+			wantAllow: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(1, time.Minute)
+			b.failure()
+			clock.advance(59 * time.Second)
+			if b.allow() {
+				t.Fatal("call allowed during cooldown")
+			}
+			clock.advance(time.Second)
+			if !b.allow() {
+				t.Fatal("probe rejected after cooldown")
+			}
+			if b.state != breakerHalfOpen {
+				t.Fatalf("state = %v, want half-open", b.state)
+			}
+			if b.allow() {
+				t.Fatal("second call allowed while the probe is in flight")
+			}
+			tt.outcome(b)
+			if b.state != tt.wantState {
+				t.Errorf("state = %v, want %v", b.state, tt.wantState)
+			}
+			if got := b.allow(); got != tt.wantAllow {
+				t.Errorf("allow = %v, want %v", got, tt.wantAllow)
+			}
+		})
+	}
+}
+func TestCircuitBreakerCooldownRestartsAfterFailedProbe(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	b.failure()
+	clock.advance(time.Minute)
+	b.allow()
+	b.failure()
+	clock.advance(30 * time.Second)
+	if b.allow() {
+		t.Error("call allowed before a full cooldown after the failed probe")
+	}
+	clock.advance(30 * time.Second)
+	if !b.allow() {
+		t.Error("probe rejected after a full cooldown")
+	}
+}
@@ -0,0 +1,183 @@
+package services
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"posts-api/internal/dto"
+	"posts-api/internal/models"
+	"posts-api/internal/repository"
+	"posts-api/pkg/utils"
+	"strings"
+)
+type CommentService interface {
+	GetComments(ctx context.Context, postID int64, viewer Actor, cursor *utils.Cursor, pageSize int) (*dto.CommentListResponse, error)
+	GetReplies(ctx context.Context, postID, commentID int64, viewer Actor, cursor *utils.Cursor, pageSize int) (*dto.CommentListResponse, error)
+	CreateComment(ctx context.Context, postID int64, req *dto.CreateCommentRequest, user *UserDTO) (*dto.CommentResponse, error)
+	UpdateComment(ctx context.Context, postID, commentID int64, req *dto.UpdateCommentRequest, actor Actor) (*dto.CommentResponse, error)
+	DeleteComment(ctx context.Context, postID, commentID int64, actor Actor) error
+}
+// replyPreviewSize is how many replies each top-level comment carries in a
+// comment listing.
+const replyPreviewSize = 3
+type commentService struct {
+	postRepo    repository.PostRepository
+	commentRepo repository.CommentRepository
+	authorizer  Authorizer
+}
+func NewCommentService(postRepo repository.PostRepository, commentRepo repository.CommentRepository, authorizer Authorizer) CommentService {
+	return &commentService{
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		authorizer:  authorizer,
+	}
+}
+func (s *commentService) GetComments(ctx context.Context, postID int64, viewer Actor, cursor *utils.Cursor, pageSize int) (*dto.CommentListResponse, error) {
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if _, err := s.visiblePost(ctx, postID, viewer); err != nil {
+		return nil, err
+	}
+	comments, err := s.commentRepo.GetComments(ctx, postID, cursor, pageSize+1, replyPreviewSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments: %w", err)
+	}
+	return newCommentPage(comments, pageSize), nil
+}
+func (s *commentService) GetReplies(ctx context.Context, postID, commentID int64, viewer Actor, cursor *utils.Cursor, pageSize int) (*dto.CommentListResponse, error) {
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if _, err := s.visiblePost(ctx, postID, viewer); err != nil {
+		return nil, err
+	}
+	parent, err := s.getComment(ctx, postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != nil {
+		return nil, NewValidationError("replies can only be listed for top-level comments")
+	}
+	replies, err := s.commentRepo.GetReplies(ctx, postID, commentID, cursor, pageSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replies: %w", err)
+	}
+	return newCommentPage(replies, pageSize), nil
+}
+// newCommentPage trims a listing fetched with one extra row to pageSize,
+// pointing next_cursor at the last comment kept when there are more.
+func newCommentPage(comments []*models.Comment, pageSize int) *dto.CommentListResponse {
+	var next *utils.Cursor
+	if len(comments) > pageSize {
+		comments = comments[:pageSize]
+		last := comments[len(comments)-1]
+		next = &utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID, Direction: utils.CursorNext}
+	}
+	return dto.NewCommentListResponse(comments, pageSize, next)
+}
+func (s *commentService) CreateComment(ctx context.Context, postID int64, req *dto.CreateCommentRequest, user *UserDTO) (*dto.CommentResponse, error) {
+	post, err := s.visiblePost(ctx, postID, NewActor(user))
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != models.PostStatusPublished {
+		return nil, NewConflictError("comments are only allowed on published posts")
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, NewValidationError("comment content cannot be blank")
+	}
+	if req.ParentID != nil {
+		parent, err := s.getComment(ctx, postID, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.ParentID != nil {
+			return nil, NewValidationError("replies can only be made to top-level comments")
+		}
+	}
+	comment := &models.Comment{
+		PostID:     postID,
+		ParentID:   req.ParentID,
+		AuthorID:   user.ID,
+		AuthorName: user.Name,
+		Content:    content,
+	}
+	if err := s.commentRepo.CreateComment(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+	return dto.NewCommentResponse(comment), nil
+}
+func (s *commentService) UpdateComment(ctx context.Context, postID, commentID int64, req *dto.UpdateCommentRequest, actor Actor) (*dto.CommentResponse, error) {
+	comment, err := s.authorizedComment(ctx, postID, commentID, actor)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, NewValidationError("comment content cannot be blank")
+	}
+	comment.Content = content
+	if err := s.commentRepo.UpdateComment(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+	return dto.NewCommentResponse(comment), nil
+}
+func (s *commentService) DeleteComment(ctx context.Context, postID, commentID int64, actor Actor) error {
+	comment, err := s.authorizedComment(ctx, postID, commentID, actor)
+	if err != nil {
+		return err
+	}
+	if err := s.commentRepo.DeleteComment(ctx, comment); err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	return nil
+}
+// visiblePost loads the post, reporting it as not found to viewers who may
+// not see it.
+func (s *commentService) visiblePost(ctx context.Context, postID int64, viewer Actor) (*models.Post, error) {
+	post, err := s.postRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError("post")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if err := s.authorizer.Authorize(viewer, ActionViewPost, post); err != nil {
+		return nil, NewNotFoundError("post")
+	}
+	return post, nil
+}
+// authorizedComment loads a comment the actor may edit or delete: their own,
+// or any comment on a post they can moderate comments on.
+func (s *commentService) authorizedComment(ctx context.Context, postID, commentID int64, actor Actor) (*models.Comment, error) {
+	post, err := s.postRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError("post")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	comment, err := s.getComment(ctx, postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.ID > 0 && comment.AuthorID == actor.ID {
+		return comment, nil
+	}
+	if err := s.authorizer.Authorize(actor, ActionModerateComments, post); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+func (s *commentService) getComment(ctx context.Context, postID, commentID int64) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetComment(ctx, postID, commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError("comment")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	return comment, nil
+}
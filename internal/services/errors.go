@@ -0,0 +1,45 @@
+package services
+import "posts-api/pkg/utils"
+// Error is a domain error carrying the kind the HTTP layer maps to a status
+// code. Two Errors match with errors.Is when their kinds are equal, so the
+// sentinels below can be used to test any error of that kind.
+type Error struct {
+	Kind    utils.ErrorKind
+	Message string
+}
+func (e *Error) Error() string {
+	return e.Message
+}
+func (e *Error) ErrorKind() utils.ErrorKind {
+	return e.Kind
+}
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind
+}
+var (
+	ErrNotFound   = &Error{Kind: utils.ErrorKindNotFound, Message: "not found"}
+	ErrConflict   = &Error{Kind: utils.ErrorKindConflict, Message: "conflict"}
+	ErrValidation = &Error{Kind: utils.ErrorKindValidation, Message: "validation failed"}
+	// ErrInvalidToken is returned when the Users API rejects a token, as
+	// opposed to failing to answer at all.
+	ErrInvalidToken = &Error{Kind: utils.ErrorKindUnauthorized, Message: "invalid token"}
+	// ErrUpstreamUnavailable is returned when the Users API cannot be reached,
+	// keeps failing, or the circuit breaker is open.
+	ErrUpstreamUnavailable = &Error{Kind: utils.ErrorKindUpstream, Message: "users API unavailable"}
+	// ErrPreconditionFailed is returned when a post changed since the version
+	// the client based its request on.
+	ErrPreconditionFailed = &Error{Kind: utils.ErrorKindPreconditionFailed, Message: "precondition failed"}
+)
+func NewNotFoundError(resource string) error {
+	return &Error{Kind: utils.ErrorKindNotFound, Message: resource + " not found"}
+}
+func NewValidationError(message string) error {
+	return &Error{Kind: utils.ErrorKindValidation, Message: message}
+}
+func NewConflictError(message string) error {
+	return &Error{Kind: utils.ErrorKindConflict, Message: message}
+}
+func NewPreconditionFailedError(message string) error {
+	return &Error{Kind: utils.ErrorKindPreconditionFailed, Message: message}
+}
@@ -0,0 +1,117 @@
+package services
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"posts-api/pkg/utils"
+	"sync"
+	"time"
+)
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+// DependencyCheck probes one external dependency. Required dependencies
+// being down makes the service not ready.
+type DependencyCheck struct {
+	Name     string
+	Required bool
+	Check    func(ctx context.Context) error
+}
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Required  bool    `json:"required"`
+	LatencyMS float64 `json:"latency_ms"`
+	// Error is only filled in with verbose errors on, as check failures can
+	// include hosts and connection details.
+	Error string `json:"error,omitempty"`
+}
+type HealthReport struct {
+	Healthy      bool               `json:"-"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	// Degraded is set when an optional dependency is down.
+	Degraded bool `json:"-"`
+}
+type HealthService interface {
+	CheckDependencies(ctx context.Context) HealthReport
+}
+type healthService struct {
+	checks  []DependencyCheck
+	timeout time.Duration
+}
+func NewHealthService(timeout time.Duration, checks ...DependencyCheck) HealthService {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &healthService{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+// CheckDependencies runs every check concurrently, each bounded by the
+// configured timeout.
+func (s *healthService) CheckDependencies(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Healthy:      true,
+		Dependencies: make([]DependencyStatus, len(s.checks)),
+	}
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+			start := time.Now()
+			err := check.Check(checkCtx)
+			status := DependencyStatus{
+				Name:      check.Name,
+				Status:    HealthStatusUp,
+				Required:  check.Required,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				status.Status = HealthStatusDown
+				slog.WarnContext(ctx, "Dependency health check failed", "dependency", check.Name, "error", err)
+				if utils.VerboseErrors() {
+					status.Error = err.Error()
+				}
+			}
+			report.Dependencies[i] = status
+		}()
+	}
+	wg.Wait()
+	for _, dep := range report.Dependencies {
+		if dep.Status == HealthStatusUp {
+			continue
+		}
+		if dep.Required {
+			report.Healthy = false
+		} else {
+			report.Degraded = true
+		}
+	}
+	return report
+}
+// NewUsersAPIHealthCheck returns a check that considers the Users API up
+// when its health endpoint answers with a non-5xx status.
+func NewUsersAPIHealthCheck(usersAPIURL, healthPath string) func(ctx context.Context) error {
+	client := &http.Client{}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, "GET", usersAPIURL+healthPath, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("users API unreachable: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("users API returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,187 @@
+package services
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"posts-api/internal/metrics"
+	"strconv"
+	"sync"
+	"time"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+var errUnknownKey = errors.New("signing key not found in JWKS")
+// jwksCache holds the Users API signing keys. Keys are refreshed on an
+// interval and on demand when a token references an unknown kid; the last
+// good key set is kept if a refresh fails so verification survives brief
+// Users API outages.
+type jwksCache struct {
+	url             string
+	httpClient      *http.Client
+	refreshInterval time.Duration
+	minRefreshGap   time.Duration
+	mu              sync.RWMutex
+	keys            map[string]crypto.PublicKey
+	fetchedAt       time.Time
+	lastAttempt     time.Time
+	refreshMu       sync.Mutex
+}
+func newJWKSCache(url string, refreshInterval time.Duration) *jwksCache {
+	if refreshInterval <= 0 {
+		refreshInterval = 15 * time.Minute
+	}
+	return &jwksCache{
+		url:             url,
+		httpClient:      &http.Client{Timeout: 10 * time.Second, Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		refreshInterval: refreshInterval,
+		minRefreshGap:   30 * time.Second,
+		keys:            make(map[string]crypto.PublicKey),
+	}
+}
+func (c *jwksCache) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	stale := time.Since(c.fetchedAt) > c.refreshInterval
+	c.mu.RUnlock()
+	if ok && !stale {
+		return key, nil
+	}
+	if err := c.refresh(ctx, !ok); err != nil {
+		slog.WarnContext(ctx, "JWKS refresh failed", "error", err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errUnknownKey
+}
+// refresh re-downloads the key set. Lookups for unknown kids are throttled
+// by minRefreshGap so forged kids can't be used to hammer the Users API.
+func (c *jwksCache) refresh(ctx context.Context, unknownKid bool) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	c.mu.RLock()
+	fresh := time.Since(c.fetchedAt) <= c.refreshInterval
+	throttled := time.Since(c.lastAttempt) < c.minRefreshGap
+	c.mu.RUnlock()
+	if throttled || (fresh && !unknownKid) {
+		return nil
+	}
+	c.mu.Lock()
+	c.lastAttempt = time.Now()
+	c.mu.Unlock()
+	keys, err := c.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+func (c *jwksCache) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		metrics.ObserveUsersAPICall("jwks", "error", time.Since(start))
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	metrics.ObserveUsersAPICall("jwks", strconv.Itoa(resp.StatusCode), time.Since(start))
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+	var set jsonWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "Skipping JWKS key", "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable signing keys")
+	}
+	return keys, nil
+}
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent out of range")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != 32 {
+			return nil, errors.New("invalid x coordinate")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil || len(y) != 32 {
+			return nil, errors.New("invalid y coordinate")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid curve point: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+func decodeBase64URLInt(str string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,110 @@
+package services
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strconv"
+	"time"
+)
+type JWTConfig struct {
+	JWKSURL         string
+	HMACSecret      string
+	Issuer          string
+	Audience        string
+	RefreshInterval time.Duration
+	Leeway          time.Duration
+}
+type userClaims struct {
+	jwt.RegisteredClaims
+	UserID *int64 `json:"userId,omitempty"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+// jwtUserService verifies tokens issued by the Users API locally instead of
+// calling /auth/me, so authentication keeps working while it is unreachable.
+type jwtUserService struct {
+	parser     *jwt.Parser
+	jwks       *jwksCache
+	hmacSecret []byte
+}
+func NewJWTUserService(cfg JWTConfig) (UserService, error) {
+	if cfg.JWKSURL == "" && cfg.HMACSecret == "" {
+		return nil, errors.New("JWT verification requires a JWKS URL or an HMAC secret")
+	}
+	var methods []string
+	svc := &jwtUserService{}
+	if cfg.JWKSURL != "" {
+		svc.jwks = newJWKSCache(cfg.JWKSURL, cfg.RefreshInterval)
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+	if cfg.HMACSecret != "" {
+		svc.hmacSecret = []byte(cfg.HMACSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	svc.parser = jwt.NewParser(opts...)
+	return svc, nil
+}
+func (s *jwtUserService) GetUserFromToken(ctx context.Context, token string) (*UserDTO, error) {
+	return s.ValidateToken(ctx, token)
+}
+func (s *jwtUserService) ValidateToken(ctx context.Context, token string) (*UserDTO, error) {
+	var claims userClaims
+	keyFunc := func(t *jwt.Token) (interface{}, error) {
+		return s.keyFunc(ctx, t)
+	}
+	if _, err := s.parser.ParseWithClaims(token, &claims, keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	userID, err := claims.userID()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return &UserDTO{
+		ID:    userID,
+		Name:  claims.Name,
+		Email: claims.Email,
+		Role:  claims.Role,
+	}, nil
+}
+func (s *jwtUserService) keyFunc(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if s.hmacSecret == nil {
+			return nil, errors.New("HMAC tokens are not accepted")
+		}
+		return s.hmacSecret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if s.jwks == nil {
+			return nil, errors.New("asymmetric tokens are not accepted")
+		}
+		kid, _ := token.Header["kid"].(string)
+		return s.jwks.key(ctx, kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+// userID prefers the Users API's userId claim and falls back to a numeric
+// subject.
+func (c *userClaims) userID() (int64, error) {
+	if c.UserID != nil && *c.UserID > 0 {
+		return *c.UserID, nil
+	}
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("token has no valid user id claim")
+	}
+	return id, nil
+}
@@ -0,0 +1,219 @@
+package services
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"github.com/golang-jwt/jwt/v5"
+)
+const testHMACSecret = "test-secret"
+// stubJWKS serves a JWKS document holding the public halves of its keys and
+// counts the fetches.
+type stubJWKS struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches atomic.Int32
+	server  *httptest.Server
+}
+func newStubJWKS(t *testing.T, kids ...string) *stubJWKS {
+	t.Helper()
+	s := &stubJWKS{keys: make(map[string]*rsa.PrivateKey)}
+	for _, kid := range kids {
+		s.addKey(t, kid)
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var set jsonWebKeySet
+		for kid, key := range s.keys {
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kid: kid,
+				Kty: "RSA",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+func (s *stubJWKS) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = key
+	return key
+}
+func (s *stubJWKS) key(kid string) *rsa.PrivateKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys[kid]
+}
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+// validClaims returns claims that pass every check in the test configs.
+func validClaims(changes jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"sub":   "42",
+		"iss":   "users-api",
+		"aud":   "posts-api",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"name":  "Ada",
+		"email": "ada@example.com",
+		"role":  "user",
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+func TestJWTUserServiceValidateToken(t *testing.T) {
+	jwks := newStubJWKS(t, "key-1")
+	publicPEM, err := x509.MarshalPKIXPublicKey(&jwks.key("key-1").PublicKey)
+	if err != nil {
+		t.Fatalf("failed to encode public key: %v", err)
+	}
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM})
+	hmacConfig := JWTConfig{HMACSecret: testHMACSecret, Issuer: "users-api", Audience: "posts-api"}
+	jwksConfig := JWTConfig{JWKSURL: jwks.server.URL, Issuer: "users-api", Audience: "posts-api"}
+	hs256 := func(secret string, changes jwt.MapClaims) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodHS256, []byte(secret), "", validClaims(changes))
+		}
+	}
+	rs256 := func(kid string, changes jwt.MapClaims) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodRS256, jwks.key(kid), kid, validClaims(changes))
+		}
+	}
+	tests := []struct {
+		name   string
+		cfg    JWTConfig
+		token  func(t *testing.T) string
+		wantID int64
+	}{
+		{name: "HS256 with the right secret", cfg: hmacConfig, token: hs256(testHMACSecret, nil), wantID: 42},
+		{name: "HS256 with a wrong secret", cfg: hmacConfig, token: hs256("other-secret", nil)},
+		{name: "RS256 with a known kid", cfg: jwksConfig, token: rs256("key-1", nil), wantID: 42},
+		{name: "RS256 without a kid", cfg: jwksConfig, token: func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodRS256, jwks.key("key-1"), "", validClaims(nil))
+		}},
+		{name: "HS256 when only JWKS is configured", cfg: jwksConfig, token: hs256(testHMACSecret, nil)},
+		{name: "HS256 signed with the JWKS public key", cfg: jwksConfig, token: func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodHS256, publicPEM, "key-1", validClaims(nil))
+		}},
+		{name: "RS256 when only a secret is configured", cfg: hmacConfig, token: rs256("key-1", nil)},
+		{name: "unsigned token", cfg: hmacConfig, token: func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims(nil))
+		}},
+		{name: "missing exp", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"exp": nil})},
+		{name: "expired", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})},
+		{name: "issuer mismatch", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"iss": "someone-else"})},
+		{name: "audience mismatch", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"aud": "other-api"})},
+		{name: "userId preferred over sub", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"userId": 7}), wantID: 7},
+		{name: "non-positive userId falls back to sub", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"userId": 0}), wantID: 42},
+		{name: "non-numeric sub without userId", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"sub": "ada"})},
+		{name: "no user id at all", cfg: hmacConfig, token: hs256(testHMACSecret, jwt.MapClaims{"sub": nil})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := NewJWTUserService(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to create service: %v", err)
+			}
+			user, err := svc.ValidateToken(context.Background(), tt.token(t))
+			if tt.wantID == 0 {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("ValidateToken = %+v, %v, want ErrInvalidToken", user, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateToken failed: %v", err)
+			}
+			if user.ID != tt.wantID || user.Name != "Ada" || user.Email != "ada@example.com" || user.Role != "user" {
+				t.Errorf("user = %+v, want ID %d with the token's profile", user, tt.wantID)
+			}
+		})
+	}
+}
+func TestJWTUserServiceRefreshesJWKSForUnknownKid(t *testing.T) {
+	ctx := context.Background()
+	jwks := newStubJWKS(t, "key-1")
+	svc, err := NewJWTUserService(JWTConfig{JWKSURL: jwks.server.URL})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	cache := svc.(*jwtUserService).jwks
+	cache.minRefreshGap = 0
+	if _, err := svc.ValidateToken(ctx, signToken(t, jwt.SigningMethodRS256, jwks.key("key-1"), "key-1", validClaims(nil))); err != nil {
+		t.Fatalf("ValidateToken with key-1 failed: %v", err)
+	}
+	if _, err := svc.ValidateToken(ctx, signToken(t, jwt.SigningMethodRS256, jwks.key("key-1"), "key-1", validClaims(nil))); err != nil {
+		t.Fatalf("second ValidateToken with key-1 failed: %v", err)
+	}
+	if got := jwks.fetches.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times for a known kid, want 1", got)
+	}
+	// The Users API rotates in a new key; the first token using it triggers
+	// a refresh instead of being rejected.
+	rotated := jwks.addKey(t, "key-2")
+	if _, err := svc.ValidateToken(ctx, signToken(t, jwt.SigningMethodRS256, rotated, "key-2", validClaims(nil))); err != nil {
+		t.Fatalf("ValidateToken with the rotated key failed: %v", err)
+	}
+	if got := jwks.fetches.Load(); got != 2 {
+		t.Errorf("JWKS fetched %d times after an unknown kid, want 2", got)
+	}
+	// A kid that is still unknown after the refresh is rejected.
+	forged := signToken(t, jwt.SigningMethodRS256, rotated, "key-3", validClaims(nil))
+	if _, err := svc.ValidateToken(ctx, forged); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("ValidateToken with an unknown kid = %v, want ErrInvalidToken", err)
+	}
+}
+func TestJWTUserServiceThrottlesUnknownKidRefreshes(t *testing.T) {
+	ctx := context.Background()
+	jwks := newStubJWKS(t, "key-1")
+	svc, err := NewJWTUserService(JWTConfig{JWKSURL: jwks.server.URL})
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	key := jwks.key("key-1")
+	for i := 0; i < 5; i++ {
+		svc.ValidateToken(ctx, signToken(t, jwt.SigningMethodRS256, key, "forged", validClaims(nil)))
+	}
+	if got := jwks.fetches.Load(); got != 1 {
+		t.Errorf("JWKS fetched %d times for repeated unknown kids, want 1", got)
+	}
+}
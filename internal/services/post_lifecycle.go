@@ -0,0 +1,114 @@
+package services
+import (
+	"context"
+	"errors"
+	"fmt"
+	"posts-api/internal/dto"
+	"posts-api/internal/models"
+	"posts-api/internal/repository"
+	"slices"
+	"time"
+	"gorm.io/gorm"
+)
+// PostTransition is a change of a post's lifecycle status.
+type PostTransition string
+const (
+	TransitionPublish   PostTransition = "publish"
+	TransitionUnpublish PostTransition = "unpublish"
+	TransitionArchive   PostTransition = "archive"
+)
+var postTransitions = map[PostTransition]struct {
+	from []string
+	to   string
+}{
+	TransitionPublish: {
+		from: []string{models.PostStatusDraft, models.PostStatusScheduled, models.PostStatusArchived},
+		to:   models.PostStatusPublished,
+	},
+	TransitionUnpublish: {
+		from: []string{models.PostStatusScheduled, models.PostStatusPublished},
+		to:   models.PostStatusDraft,
+	},
+	TransitionArchive: {
+		from: []string{models.PostStatusDraft, models.PostStatusScheduled, models.PostStatusPublished},
+		to:   models.PostStatusArchived,
+	},
+}
+// TransitionPost moves a post to the status the transition leads to. It
+// fails with a conflict when the post's current status doesn't allow it.
+func (s *postService) TransitionPost(ctx context.Context, id int64, transition PostTransition, actor Actor, ifMatch *int64) (*dto.PostResponse, error) {
+	rule, ok := postTransitions[transition]
+	if !ok {
+		return nil, fmt.Errorf("unknown post transition %q", transition)
+	}
+	post, err := s.postRepo.GetPostByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError("post")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if err := s.authorizer.Authorize(actor, ActionPublishPost, post); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(post, ifMatch); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(rule.from, post.Status) {
+		return nil, NewConflictError(fmt.Sprintf("cannot %s a post that is %s", transition, post.Status))
+	}
+	// Any transition settles a scheduled post, so its schedule is dropped.
+	post.Status = rule.to
+	post.PublishAt = nil
+	switch transition {
+	case TransitionPublish:
+		now := time.Now()
+		post.PublishedAt = &now
+	case TransitionUnpublish:
+		post.PublishedAt = nil
+	}
+	if err := s.postRepo.UpdatePost(ctx, post, actor.ID); err != nil {
+		return nil, versionConflictOr(err, "failed to update post status")
+	}
+	response := &dto.PostResponse{}
+	response.FromModel(post)
+	return response, nil
+}
+// schedulePost sets a draft or scheduled post to be published at publishAt,
+// which must lie in the future.
+func schedulePost(post *models.Post, publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return NewValidationError("publish_at must be in the future")
+	}
+	if post.Status != models.PostStatusDraft && post.Status != models.PostStatusScheduled {
+		return NewConflictError(fmt.Sprintf("cannot schedule a post that is %s", post.Status))
+	}
+	post.Status = models.PostStatusScheduled
+	post.PublishAt = &publishAt
+	return nil
+}
+// GetDrafts lists the author's drafts and scheduled posts, most recently
+// edited first.
+func (s *postService) GetDrafts(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+	filter := repository.PostFilter{
+		AuthorIDs: []int64{authorID},
+		Statuses:  []string{models.PostStatusDraft, models.PostStatusScheduled},
+	}
+	sort := repository.PostSort{Field: "updated_at", Desc: true}
+	posts, err := s.postRepo.GetPostsPaginated(ctx, filter, sort, offset, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get drafts: %w", err)
+	}
+	total, err := s.postRepo.GetTotalPosts(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total drafts count: %w", err)
+	}
+	return dto.NewPostListResponse(posts, total, page, pageSize), nil
+}
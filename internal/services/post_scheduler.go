@@ -0,0 +1,51 @@
+package services
+import (
+	"context"
+	"log/slog"
+	"posts-api/internal/repository"
+	"time"
+)
+type PostSchedulerConfig struct {
+	// Interval is how often due posts are looked for, and so roughly how late
+	// a scheduled post can go live.
+	Interval  time.Duration
+	BatchSize int
+}
+// PostScheduler publishes scheduled posts once their publish_at has passed.
+// Run blocks until ctx is cancelled; it is safe to run on every replica.
+type PostScheduler interface {
+	Run(ctx context.Context)
+	PublishDue(ctx context.Context) (int64, error)
+}
+type postScheduler struct {
+	postRepo repository.PostRepository
+	cfg      PostSchedulerConfig
+}
+func NewPostScheduler(postRepo repository.PostRepository, cfg PostSchedulerConfig) PostScheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &postScheduler{postRepo: postRepo, cfg: cfg}
+}
+func (s *postScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if published, err := s.PublishDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Publishing scheduled posts failed", "error", err, "published", published)
+		} else if published > 0 {
+			slog.InfoContext(ctx, "Published scheduled posts", "published", published)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+func (s *postScheduler) PublishDue(ctx context.Context) (int64, error) {
+	return s.postRepo.PublishDuePosts(ctx, time.Now(), s.cfg.BatchSize)
+}
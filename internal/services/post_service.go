@@ -1,184 +1,345 @@
 package services
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math"
 	"posts-api/internal/dto"
+	"posts-api/internal/models"
 	"posts-api/internal/repository"
+	"posts-api/pkg/utils"
+	"time"
 	"gorm.io/gorm"
 )
 type PostService interface {
-	CreatePost(req *dto.CreatePostRequest, authorID int64, token string) (*dto.PostResponse, error)
-	GetPostByID(id int64) (*dto.PostResponse, error)
-	GetAllPosts(page, pageSize int) (*dto.PostListResponse, error)
-	UpdatePost(id int64, req *dto.UpdatePostRequest, authorID int64) (*dto.PostResponse, error)
-	DeletePost(id int64, authorID int64) error
-	GetPostsByAuthor(authorID int64, page, pageSize int) (*dto.PostListResponse, error)
+	CreatePost(ctx context.Context, req *dto.CreatePostRequest, authorID int64, token string) (*dto.PostResponse, error)
+	// GetPostByID reports unpublished posts as not found unless viewer is
+	// their author or a moderator; anonymous viewers are the zero Actor.
+	GetPostByID(ctx context.Context, id int64, viewer Actor) (*dto.PostResponse, error)
+	GetAllPosts(ctx context.Context, query *dto.PostListQuery, page, pageSize int) (*dto.PostListResponse, error)
+	// UpdatePost and DeletePost only apply when ifMatch is nil or equal to
+	// the post's current version.
+	UpdatePost(ctx context.Context, id int64, req *dto.UpdatePostRequest, actor Actor, ifMatch *int64) (*dto.PostResponse, error)
+	DeletePost(ctx context.Context, id int64, actor Actor, ifMatch *int64) error
+	GetPostsByAuthor(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error)
+	GetAllPostsByCursor(ctx context.Context, query *dto.PostListQuery, cursor *utils.Cursor, pageSize int) (*dto.PostListResponse, error)
+	GetPostsByAuthorByCursor(ctx context.Context, authorID int64, cursor *utils.Cursor, pageSize int) (*dto.PostListResponse, error)
+	SearchPosts(ctx context.Context, query string, page, pageSize int) (*dto.PostSearchResponse, error)
+	GetTrashedPosts(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error)
+	RestorePost(ctx context.Context, id int64, actor Actor) (*dto.PostResponse, error)
+	TransitionPost(ctx context.Context, id int64, transition PostTransition, actor Actor, ifMatch *int64) (*dto.PostResponse, error)
+	GetDrafts(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error)
 }
 type postService struct {
 	postRepo    repository.PostRepository
 	userService UserService
+	authorizer  Authorizer
 }
-func NewPostService(postRepo repository.PostRepository, userService UserService) PostService {
+func NewPostService(postRepo repository.PostRepository, userService UserService, authorizer Authorizer) PostService {
 	return &postService{
 		postRepo:    postRepo,
 		userService: userService,
+		authorizer:  authorizer,
 	}
 }
-func (s *postService) CreatePost(req *dto.CreatePostRequest, authorID int64, token string) (*dto.PostResponse, error) {
-	userData, err := s.userService.GetUserFromToken(token)
+func (s *postService) CreatePost(ctx context.Context, req *dto.CreatePostRequest, authorID int64, token string) (*dto.PostResponse, error) {
+	userData, err := s.userService.GetUserFromToken(ctx, token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user information: %w", err)
 	}
 	
 	if userData.ID != authorID {
-		return nil, errors.New("token user ID does not match provided author ID")
+		return nil, &ForbiddenError{Action: ActionCreatePost, Reason: "token user ID does not match provided author ID"}
+	}
+	if err := s.authorizer.Authorize(NewActor(userData), ActionCreatePost, nil); err != nil {
+		return nil, err
 	}
 	
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if req.PublishAt != nil {
+		if req.Status == models.PostStatusPublished {
+			return nil, NewValidationError("publish_at cannot be combined with status published")
+		}
+		if !req.PublishAt.After(time.Now()) {
+			return nil, NewValidationError("publish_at must be in the future")
+		}
+	}
 	post := req.ToModelWithAuthor(authorID, userData.Name, userData.Email)
-	if err := s.postRepo.CreatePost(post); err != nil {
+	post.Tags = tags
+	if err := s.postRepo.CreatePost(ctx, post); err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 	response := &dto.PostResponse{}
 	response.FromModel(post)
 	return response, nil
 }
-func (s *postService) GetPostByID(id int64) (*dto.PostResponse, error) {
-	post, err := s.postRepo.GetPostByID(id)
+func (s *postService) GetPostByID(ctx context.Context, id int64, viewer Actor) (*dto.PostResponse, error) {
+	post, err := s.postRepo.GetPostByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, NewNotFoundError("post")
 		}
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
+	if err := s.authorizer.Authorize(viewer, ActionViewPost, post); err != nil {
+		return nil, NewNotFoundError("post")
+	}
 	response := &dto.PostResponse{}
 	response.FromModel(post)
 	return response, nil
 }
-func (s *postService) GetAllPosts(page, pageSize int) (*dto.PostListResponse, error) {
+func (s *postService) GetAllPosts(ctx context.Context, query *dto.PostListQuery, page, pageSize int) (*dto.PostListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
 	if pageSize < 1 {
 		pageSize = 10
 	}
-	posts, err := s.postRepo.GetAllPosts()
+	offset := (page - 1) * pageSize
+	filter := postFilterFromQuery(query)
+	sort := repository.DefaultPostSort
+	if query != nil && query.Sort != "" {
+		sort.Field = query.Sort
+	}
+	if query != nil {
+		sort.Desc = query.SortDesc()
+	}
+	posts, err := s.postRepo.GetPostsPaginated(ctx, filter, sort, offset, pageSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get posts: %w", err)
 	}
-	total, err := s.postRepo.GetTotalPosts()
+	total, err := s.postRepo.GetTotalPosts(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total posts count: %w", err)
 	}
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
-	offset := (page - 1) * pageSize
-	var paginatedPosts []*dto.PostResponse
-	if offset < len(posts) {
-		end := offset + pageSize
-		if end > len(posts) {
-			end = len(posts)
-		}
-		
-		postSlice := posts[offset:end]
-		paginatedPosts = make([]*dto.PostResponse, len(postSlice))
-		for i, post := range postSlice {
-			response := &dto.PostResponse{}
-			response.FromModel(post)
-			
-			
-			paginatedPosts[i] = response
-		}
-	} else {
-		paginatedPosts = []*dto.PostResponse{}
-	}
-	posts_slice := make([]dto.PostResponse, len(paginatedPosts))
-	for i, post := range paginatedPosts {
-		posts_slice[i] = *post
-	}
-	return &dto.PostListResponse{
-		Posts:      posts_slice,
-		TotalCount: total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	}, nil
+	return dto.NewPostListResponse(posts, total, page, pageSize), nil
 }
-func (s *postService) UpdatePost(id int64, req *dto.UpdatePostRequest, authorID int64) (*dto.PostResponse, error) {
-	existingPost, err := s.postRepo.GetPostByID(id)
+func (s *postService) UpdatePost(ctx context.Context, id int64, req *dto.UpdatePostRequest, actor Actor, ifMatch *int64) (*dto.PostResponse, error) {
+	existingPost, err := s.postRepo.GetPostByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, NewNotFoundError("post")
 		}
 		return nil, fmt.Errorf("failed to get post: %w", err)
 	}
-	if existingPost.AuthorID != authorID {
-		return nil, errors.New("unauthorized: only the author can update this post")
+	if err := s.authorizer.Authorize(actor, ActionUpdatePost, existingPost); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(existingPost, ifMatch); err != nil {
+		return nil, err
 	}
 	req.UpdateModel(existingPost)
-	if err := s.postRepo.UpdatePost(existingPost); err != nil {
-		return nil, fmt.Errorf("failed to update post: %w", err)
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		existingPost.Tags = tags
+	}
+	// A full replacement carries the current schedule along with every other
+	// field; only a new time reschedules the post.
+	if req.PublishAt != nil && (existingPost.PublishAt == nil || !req.PublishAt.Equal(*existingPost.PublishAt)) {
+		if err := schedulePost(existingPost, *req.PublishAt); err != nil {
+			return nil, err
+		}
+	} else if req.Unschedule && existingPost.Status == models.PostStatusScheduled {
+		existingPost.Status = models.PostStatusDraft
+		existingPost.PublishAt = nil
+	}
+	if err := s.postRepo.UpdatePost(ctx, existingPost, actor.ID); err != nil {
+		return nil, versionConflictOr(err, "failed to update post")
 	}
 	response := &dto.PostResponse{}
 	response.FromModel(existingPost)
 	return response, nil
 }
-func (s *postService) DeletePost(id int64, authorID int64) error {
-	existingPost, err := s.postRepo.GetPostByID(id)
+func (s *postService) DeletePost(ctx context.Context, id int64, actor Actor, ifMatch *int64) error {
+	existingPost, err := s.postRepo.GetPostByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("post not found")
+			return NewNotFoundError("post")
 		}
 		return fmt.Errorf("failed to get post: %w", err)
 	}
-	if existingPost.AuthorID != authorID {
-		return errors.New("unauthorized: only the author can delete this post")
+	if err := s.authorizer.Authorize(actor, ActionDeletePost, existingPost); err != nil {
+		return err
+	}
+	if err := checkVersion(existingPost, ifMatch); err != nil {
+		return err
 	}
-	if err := s.postRepo.DeletePost(id); err != nil {
-		return fmt.Errorf("failed to delete post: %w", err)
+	if err := s.postRepo.DeletePost(ctx, id, existingPost.Version); err != nil {
+		return versionConflictOr(err, "failed to delete post")
 	}
 	return nil
 }
-func (s *postService) GetPostsByAuthor(authorID int64, page, pageSize int) (*dto.PostListResponse, error) {
+func (s *postService) GetPostsByAuthor(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
 	if pageSize < 1 {
 		pageSize = 10
 	}
-	posts, err := s.postRepo.GetByAuthorID(authorID)
+	offset := (page - 1) * pageSize
+	filter := repository.PostFilter{AuthorIDs: []int64{authorID}, Statuses: publicStatuses}
+	posts, err := s.postRepo.GetPostsPaginated(ctx, filter, repository.DefaultPostSort, offset, pageSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get posts by author: %w", err)
 	}
-	total := int64(len(posts))
-	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
+	total, err := s.postRepo.GetTotalPosts(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total posts count by author: %w", err)
+	}
+	return dto.NewPostListResponse(posts, total, page, pageSize), nil
+}
+func (s *postService) GetAllPostsByCursor(ctx context.Context, query *dto.PostListQuery, cursor *utils.Cursor, pageSize int) (*dto.PostListResponse, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	filter := postFilterFromQuery(query)
+	posts, err := s.postRepo.GetPostsByCursor(ctx, filter, cursor, pageSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+	total, err := s.postRepo.GetTotalPosts(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total posts count: %w", err)
+	}
+	posts, next, prev := cursorPage(posts, cursor, pageSize)
+	return dto.NewCursorPostListResponse(posts, total, pageSize, next, prev), nil
+}
+func (s *postService) GetPostsByAuthorByCursor(ctx context.Context, authorID int64, cursor *utils.Cursor, pageSize int) (*dto.PostListResponse, error) {
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	filter := repository.PostFilter{AuthorIDs: []int64{authorID}, Statuses: publicStatuses}
+	posts, err := s.postRepo.GetPostsByCursor(ctx, filter, cursor, pageSize+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts by author: %w", err)
+	}
+	total, err := s.postRepo.GetTotalPosts(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total posts count by author: %w", err)
+	}
+	posts, next, prev := cursorPage(posts, cursor, pageSize)
+	return dto.NewCursorPostListResponse(posts, total, pageSize, next, prev), nil
+}
+func (s *postService) SearchPosts(ctx context.Context, query string, page, pageSize int) (*dto.PostSearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+	matches, err := s.postRepo.SearchPosts(ctx, query, offset, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+	total, err := s.postRepo.GetTotalSearchResults(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total search results count: %w", err)
+	}
+	results := make([]dto.PostSearchResult, len(matches))
+	for i, match := range matches {
+		results[i] = dto.NewPostSearchResult(&match.Post, match.Rank, match.TitleHighlight, match.Snippet)
+	}
+	return dto.NewPostSearchResponse(query, results, total, page, pageSize), nil
+}
+func (s *postService) GetTrashedPosts(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
 	offset := (page - 1) * pageSize
-	var paginatedPosts []*dto.PostResponse
-	if offset < len(posts) {
-		end := offset + pageSize
-		if end > len(posts) {
-			end = len(posts)
+	posts, err := s.postRepo.GetTrashedPosts(ctx, authorID, offset, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed posts: %w", err)
+	}
+	total, err := s.postRepo.GetTotalTrashedPosts(ctx, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total trashed posts count: %w", err)
+	}
+	return dto.NewPostListResponse(posts, total, page, pageSize), nil
+}
+func (s *postService) RestorePost(ctx context.Context, id int64, actor Actor) (*dto.PostResponse, error) {
+	post, err := s.postRepo.GetPostByIDWithTrashed(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError("post")
 		}
-		postSlice := posts[offset:end]
-		paginatedPosts = make([]*dto.PostResponse, len(postSlice))
-		for i, post := range postSlice {
-			response := &dto.PostResponse{}
-			response.FromModel(post)
-			
-			
-			paginatedPosts[i] = response
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if err := s.authorizer.Authorize(actor, ActionRestorePost, post); err != nil {
+		return nil, err
+	}
+	if !post.DeletedAt.Valid {
+		return nil, NewConflictError("post is not in the trash")
+	}
+	if err := s.postRepo.RestorePost(ctx, id, post.Version); err != nil {
+		// Another restore or the purge job got to the post first.
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, NewConflictError("post is no longer in the trash")
 		}
-	} else {
-		paginatedPosts = []*dto.PostResponse{}
-	}
-	posts_slice := make([]dto.PostResponse, len(paginatedPosts))
-	for i, post := range paginatedPosts {
-		posts_slice[i] = *post
-	}
-	return &dto.PostListResponse{
-		Posts:      posts_slice,
-		TotalCount: total,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	}, nil
+		return nil, fmt.Errorf("failed to restore post: %w", err)
+	}
+	return s.GetPostByID(ctx, id, actor)
+}
+// cursorPage trims a result fetched with one extra row down to pageSize and
+// works out which neighbouring pages exist.
+func cursorPage(posts []*models.Post, cursor *utils.Cursor, pageSize int) ([]*models.Post, *utils.Cursor, *utils.Cursor) {
+	backwards := cursor != nil && cursor.Direction == utils.CursorPrev
+	hasMore := len(posts) > pageSize
+	if hasMore {
+		if backwards {
+			posts = posts[len(posts)-pageSize:]
+		} else {
+			posts = posts[:pageSize]
+		}
+	}
+	if len(posts) == 0 {
+		return posts, nil, nil
+	}
+	var next, prev *utils.Cursor
+	first, last := posts[0], posts[len(posts)-1]
+	if hasMore || backwards {
+		next = &utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID, Direction: utils.CursorNext}
+	}
+	if (hasMore && backwards) || (cursor != nil && !backwards) {
+		prev = &utils.Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Direction: utils.CursorPrev}
+	}
+	return posts, next, prev
+}
+func checkVersion(post *models.Post, ifMatch *int64) error {
+	if ifMatch != nil && *ifMatch != post.Version {
+		return NewPreconditionFailedError(fmt.Sprintf("post is at version %d, not %d", post.Version, *ifMatch))
+	}
+	return nil
+}
+// versionConflictOr turns a lost race on a conditional write into a
+// precondition failure and wraps any other error with msg.
+func versionConflictOr(err error, msg string) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return NewPreconditionFailedError("post was modified concurrently")
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+// publicStatuses are the statuses shown by the public post listings.
+var publicStatuses = []string{models.PostStatusPublished}
+func postFilterFromQuery(query *dto.PostListQuery) repository.PostFilter {
+	if query == nil {
+		return repository.PostFilter{Statuses: publicStatuses}
+	}
+	return repository.PostFilter{
+		AuthorIDs:     query.AuthorIDs,
+		Statuses:      publicStatuses,
+		CreatedAfter:  query.CreatedAfter,
+		CreatedBefore: query.CreatedBefore,
+		UpdatedSince:  query.UpdatedSince,
+		Tags:          query.Tags,
+		MatchAllTags:  query.MatchAllTags,
+	}
 }
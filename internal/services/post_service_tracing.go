@@ -0,0 +1,114 @@
+package services
+import (
+	"context"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"posts-api/internal/dto"
+	"posts-api/internal/tracing"
+	"posts-api/pkg/utils"
+)
+type tracingPostService struct {
+	next PostService
+}
+// NewTracingPostService wraps a PostService so every call gets its own span.
+func NewTracingPostService(next PostService) PostService {
+	return &tracingPostService{next: next}
+}
+func (s *tracingPostService) CreatePost(ctx context.Context, req *dto.CreatePostRequest, authorID int64, token string) (*dto.PostResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.CreatePost", trace.WithAttributes(attribute.Int64("post.author_id", authorID)))
+	resp, err := s.next.CreatePost(ctx, req, authorID, token)
+	if resp != nil {
+		span.SetAttributes(attribute.Int64("post.id", resp.ID))
+	}
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) GetPostByID(ctx context.Context, id int64, viewer Actor) (*dto.PostResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.GetPostByID", trace.WithAttributes(actorAttributes(id, viewer)...))
+	resp, err := s.next.GetPostByID(ctx, id, viewer)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) GetAllPosts(ctx context.Context, query *dto.PostListQuery, page, pageSize int) (*dto.PostListResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.GetAllPosts", trace.WithAttributes(pageAttributes(page, pageSize)...))
+	resp, err := s.next.GetAllPosts(ctx, query, page, pageSize)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) UpdatePost(ctx context.Context, id int64, req *dto.UpdatePostRequest, actor Actor, ifMatch *int64) (*dto.PostResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.UpdatePost", trace.WithAttributes(actorAttributes(id, actor)...))
+	resp, err := s.next.UpdatePost(ctx, id, req, actor, ifMatch)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) DeletePost(ctx context.Context, id int64, actor Actor, ifMatch *int64) error {
+	ctx, span := tracing.Start(ctx, "PostService.DeletePost", trace.WithAttributes(actorAttributes(id, actor)...))
+	err := s.next.DeletePost(ctx, id, actor, ifMatch)
+	tracing.End(span, err)
+	return err
+}
+func (s *tracingPostService) GetPostsByAuthor(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error) {
+	attrs := append(pageAttributes(page, pageSize), attribute.Int64("post.author_id", authorID))
+	ctx, span := tracing.Start(ctx, "PostService.GetPostsByAuthor", trace.WithAttributes(attrs...))
+	resp, err := s.next.GetPostsByAuthor(ctx, authorID, page, pageSize)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) GetAllPostsByCursor(ctx context.Context, query *dto.PostListQuery, cursor *utils.Cursor, pageSize int) (*dto.PostListResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.GetAllPostsByCursor", trace.WithAttributes(attribute.Int("page_size", pageSize)))
+	resp, err := s.next.GetAllPostsByCursor(ctx, query, cursor, pageSize)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) GetPostsByAuthorByCursor(ctx context.Context, authorID int64, cursor *utils.Cursor, pageSize int) (*dto.PostListResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.GetPostsByAuthorByCursor", trace.WithAttributes(
+		attribute.Int64("post.author_id", authorID),
+		attribute.Int("page_size", pageSize),
+	))
+	resp, err := s.next.GetPostsByAuthorByCursor(ctx, authorID, cursor, pageSize)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) SearchPosts(ctx context.Context, query string, page, pageSize int) (*dto.PostSearchResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.SearchPosts", trace.WithAttributes(pageAttributes(page, pageSize)...))
+	resp, err := s.next.SearchPosts(ctx, query, page, pageSize)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) GetTrashedPosts(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error) {
+	attrs := append(pageAttributes(page, pageSize), attribute.Int64("post.author_id", authorID))
+	ctx, span := tracing.Start(ctx, "PostService.GetTrashedPosts", trace.WithAttributes(attrs...))
+	resp, err := s.next.GetTrashedPosts(ctx, authorID, page, pageSize)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) RestorePost(ctx context.Context, id int64, actor Actor) (*dto.PostResponse, error) {
+	ctx, span := tracing.Start(ctx, "PostService.RestorePost", trace.WithAttributes(actorAttributes(id, actor)...))
+	resp, err := s.next.RestorePost(ctx, id, actor)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) TransitionPost(ctx context.Context, id int64, transition PostTransition, actor Actor, ifMatch *int64) (*dto.PostResponse, error) {
+	attrs := append(actorAttributes(id, actor), attribute.String("post.transition", string(transition)))
+	ctx, span := tracing.Start(ctx, "PostService.TransitionPost", trace.WithAttributes(attrs...))
+	resp, err := s.next.TransitionPost(ctx, id, transition, actor, ifMatch)
+	tracing.End(span, err)
+	return resp, err
+}
+func (s *tracingPostService) GetDrafts(ctx context.Context, authorID int64, page, pageSize int) (*dto.PostListResponse, error) {
+	attrs := append(pageAttributes(page, pageSize), attribute.Int64("post.author_id", authorID))
+	ctx, span := tracing.Start(ctx, "PostService.GetDrafts", trace.WithAttributes(attrs...))
+	resp, err := s.next.GetDrafts(ctx, authorID, page, pageSize)
+	tracing.End(span, err)
+	return resp, err
+}
+func pageAttributes(page, pageSize int) []attribute.KeyValue {
+	return []attribute.KeyValue{attribute.Int("page", page), attribute.Int("page_size", pageSize)}
+}
+func actorAttributes(postID int64, actor Actor) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("post.id", postID),
+		attribute.Int64("actor.id", actor.ID),
+		attribute.String("actor.role", actor.Role),
+	}
+}
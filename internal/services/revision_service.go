@@ -0,0 +1,135 @@
+package services
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"posts-api/internal/dto"
+	"posts-api/internal/models"
+	"posts-api/internal/repository"
+	"posts-api/pkg/utils"
+)
+type RevisionService interface {
+	GetRevisions(ctx context.Context, postID int64, actor Actor, page, pageSize int) (*dto.PostRevisionListResponse, error)
+	GetRevision(ctx context.Context, postID int64, revision int, actor Actor) (*dto.PostRevisionResponse, error)
+	// DiffRevisions compares revision from with revision to, or with the
+	// current post when to is nil.
+	DiffRevisions(ctx context.Context, postID int64, from int, to *int, actor Actor) (*dto.PostRevisionDiffResponse, error)
+	RestoreRevision(ctx context.Context, postID int64, revision int, actor Actor, ifMatch *int64) (*dto.PostResponse, error)
+}
+type revisionService struct {
+	postRepo     repository.PostRepository
+	revisionRepo repository.PostRevisionRepository
+	authorizer   Authorizer
+}
+func NewRevisionService(postRepo repository.PostRepository, revisionRepo repository.PostRevisionRepository, authorizer Authorizer) RevisionService {
+	return &revisionService{
+		postRepo:     postRepo,
+		revisionRepo: revisionRepo,
+		authorizer:   authorizer,
+	}
+}
+func (s *revisionService) GetRevisions(ctx context.Context, postID int64, actor Actor, page, pageSize int) (*dto.PostRevisionListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if _, err := s.authorizedPost(ctx, postID, actor); err != nil {
+		return nil, err
+	}
+	offset := (page - 1) * pageSize
+	revisions, err := s.revisionRepo.GetRevisions(ctx, postID, offset, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	total, err := s.revisionRepo.GetTotalRevisions(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total revisions count: %w", err)
+	}
+	return dto.NewPostRevisionListResponse(revisions, total, page, pageSize), nil
+}
+func (s *revisionService) GetRevision(ctx context.Context, postID int64, revision int, actor Actor) (*dto.PostRevisionResponse, error) {
+	if _, err := s.authorizedPost(ctx, postID, actor); err != nil {
+		return nil, err
+	}
+	rev, err := s.getRevision(ctx, postID, revision)
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewPostRevisionResponse(rev), nil
+}
+func (s *revisionService) DiffRevisions(ctx context.Context, postID int64, from int, to *int, actor Actor) (*dto.PostRevisionDiffResponse, error) {
+	post, err := s.authorizedPost(ctx, postID, actor)
+	if err != nil {
+		return nil, err
+	}
+	fromRev, err := s.getRevision(ctx, postID, from)
+	if err != nil {
+		return nil, err
+	}
+	toTitle, toContent := post.Title, post.Content
+	if to != nil {
+		toRev, err := s.getRevision(ctx, postID, *to)
+		if err != nil {
+			return nil, err
+		}
+		toTitle, toContent = toRev.Title, toRev.Content
+	}
+	return &dto.PostRevisionDiffResponse{
+		PostID:  postID,
+		From:    from,
+		To:      to,
+		Title:   utils.DiffLines(fromRev.Title, toTitle),
+		Content: utils.DiffLines(fromRev.Content, toContent),
+	}, nil
+}
+// RestoreRevision copies a revision's title and content back onto the post.
+// The edit is recorded like any other, so the replaced content becomes a
+// new revision and nothing is lost. Like other writes it only applies when
+// ifMatch, if given, is the post's current version.
+func (s *revisionService) RestoreRevision(ctx context.Context, postID int64, revision int, actor Actor, ifMatch *int64) (*dto.PostResponse, error) {
+	post, err := s.authorizedPost(ctx, postID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(post, ifMatch); err != nil {
+		return nil, err
+	}
+	rev, err := s.getRevision(ctx, postID, revision)
+	if err != nil {
+		return nil, err
+	}
+	post.Title = rev.Title
+	post.Content = rev.Content
+	if err := s.postRepo.UpdatePost(ctx, post, actor.ID); err != nil {
+		return nil, versionConflictOr(err, "failed to restore revision")
+	}
+	return dto.NewPostResponse(post), nil
+}
+// authorizedPost loads the post and checks the actor may edit it, which is
+// also what it takes to read its history.
+func (s *revisionService) authorizedPost(ctx context.Context, postID int64, actor Actor) (*models.Post, error) {
+	post, err := s.postRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError("post")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if err := s.authorizer.Authorize(actor, ActionUpdatePost, post); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+func (s *revisionService) getRevision(ctx context.Context, postID int64, revision int) (*models.PostRevision, error) {
+	rev, err := s.revisionRepo.GetRevision(ctx, postID, revision)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewNotFoundError("revision")
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return rev, nil
+}
@@ -0,0 +1,72 @@
+package services
+import (
+	"context"
+	"errors"
+	"posts-api/internal/models"
+	"posts-api/internal/repository"
+	"posts-api/internal/testutil"
+	"testing"
+	"time"
+)
+func TestRestoreRevisionRecordsNewRevision(t *testing.T) {
+	db := testutil.OpenDB(t)
+	ctx := context.Background()
+	postRepo := repository.NewPostRepository(db)
+	revisionRepo := repository.NewPostRevisionRepository(db)
+	service := NewRevisionService(postRepo, revisionRepo, NewAuthorizer())
+	author := Actor{ID: 1}
+	now := time.Now()
+	post := &models.Post{
+		Title:       "First",
+		Content:     "first content",
+		AuthorID:    author.ID,
+		AuthorName:  "author",
+		AuthorEmail: "author@example.com",
+		Status:      models.PostStatusPublished,
+		PublishedAt: &now,
+	}
+	if err := postRepo.CreatePost(ctx, post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	for _, title := range []string{"Second", "Third"} {
+		post.Title = title
+		post.Content = title + " content"
+		if err := postRepo.UpdatePost(ctx, post, author.ID); err != nil {
+			t.Fatalf("failed to update post: %v", err)
+		}
+	}
+	stale := post.Version - 1
+	if _, err := service.RestoreRevision(ctx, post.ID, 1, author, &stale); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("restore with a stale If-Match = %v, want precondition failed", err)
+	}
+	restored, err := service.RestoreRevision(ctx, post.ID, 1, author, &post.Version)
+	if err != nil {
+		t.Fatalf("failed to restore revision: %v", err)
+	}
+	if restored.Title != "First" || restored.Content != "first content" {
+		t.Errorf("restored post = %q/%q, want revision 1's title and content", restored.Title, restored.Content)
+	}
+	total, err := revisionRepo.GetTotalRevisions(ctx, post.ID)
+	if err != nil {
+		t.Fatalf("failed to count revisions: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("got %d revisions, want 3 with nothing recorded by the rejected restore", total)
+	}
+	// The content the restore replaced is kept as revision N+1.
+	rev, err := revisionRepo.GetRevision(ctx, post.ID, 3)
+	if err != nil {
+		t.Fatalf("failed to get revision 3: %v", err)
+	}
+	if rev.Title != "Third" || rev.Content != "Third content" || rev.EditedBy != author.ID {
+		t.Errorf("revision 3 = %q/%q by %d, want the replaced Third content by %d", rev.Title, rev.Content, rev.EditedBy, author.ID)
+	}
+	// Revision 1 itself is left as it was.
+	first, err := revisionRepo.GetRevision(ctx, post.ID, 1)
+	if err != nil {
+		t.Fatalf("failed to get revision 1: %v", err)
+	}
+	if first.Title != "First" {
+		t.Errorf("revision 1 title = %q, want First", first.Title)
+	}
+}
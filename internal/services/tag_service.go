@@ -0,0 +1,61 @@
+package services
+import (
+	"context"
+	"fmt"
+	"posts-api/internal/dto"
+	"posts-api/internal/models"
+	"posts-api/internal/repository"
+	"posts-api/pkg/utils"
+)
+const (
+	maxTagsPerPost = 10
+	maxTagLength   = 50
+)
+type TagService interface {
+	GetTags(ctx context.Context, limit int) (*dto.TagListResponse, error)
+}
+type tagService struct {
+	tagRepo repository.TagRepository
+}
+func NewTagService(tagRepo repository.TagRepository) TagService {
+	return &tagService{
+		tagRepo: tagRepo,
+	}
+}
+func (s *tagService) GetTags(ctx context.Context, limit int) (*dto.TagListResponse, error) {
+	if limit < 1 {
+		limit = 100
+	}
+	usage, err := s.tagRepo.GetTagUsage(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	tags := make([]dto.TagResponse, len(usage))
+	for i, u := range usage {
+		tags[i] = dto.TagResponse{Slug: u.Slug, PostCount: u.PostCount}
+	}
+	return &dto.TagListResponse{Tags: tags}, nil
+}
+// normalizeTags slugifies and de-duplicates tag names, keeping their order.
+func normalizeTags(names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		slug := utils.Slugify(name)
+		if slug == "" {
+			return nil, NewValidationError(fmt.Sprintf("tag %q has no letters or digits", name))
+		}
+		if len(slug) > maxTagLength {
+			return nil, NewValidationError(fmt.Sprintf("tag %q is longer than %d characters", slug, maxTagLength))
+		}
+		if seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		tags = append(tags, models.Tag{Slug: slug})
+	}
+	if len(tags) > maxTagsPerPost {
+		return nil, NewValidationError(fmt.Sprintf("a post can have at most %d tags", maxTagsPerPost))
+	}
+	return tags, nil
+}
@@ -0,0 +1,158 @@
+package services
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"github.com/golang-jwt/jwt/v5"
+)
+type TokenCacheConfig struct {
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	MaxEntries  int
+}
+type TokenCacheStats struct {
+	Hits         uint64  `json:"hits"`
+	NegativeHits uint64  `json:"negative_hits"`
+	Misses       uint64  `json:"misses"`
+	Evictions    uint64  `json:"evictions"`
+	Size         int     `json:"size"`
+	HitRate      float64 `json:"hit_rate"`
+}
+// CachingUserService is a UserService that remembers validation results for
+// a bounded time so protected requests don't all round-trip to the Users API.
+type CachingUserService interface {
+	UserService
+	CacheStats() TokenCacheStats
+}
+type tokenCacheEntry struct {
+	key       string
+	user      *UserDTO
+	err       error
+	expiresAt time.Time
+}
+type cachingUserService struct {
+	next         UserService
+	ttl          time.Duration
+	negativeTTL  time.Duration
+	maxEntries   int
+	mu           sync.Mutex
+	entries      map[string]*list.Element
+	lru          *list.List
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+	evictions    atomic.Uint64
+	now          func() time.Time
+}
+func NewCachingUserService(next UserService, cfg TokenCacheConfig) CachingUserService {
+	if cfg.MaxEntries < 1 {
+		cfg.MaxEntries = 10000
+	}
+	return &cachingUserService{
+		next:        next,
+		ttl:         cfg.TTL,
+		negativeTTL: cfg.NegativeTTL,
+		maxEntries:  cfg.MaxEntries,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+		now:         time.Now,
+	}
+}
+func (s *cachingUserService) GetUserFromToken(ctx context.Context, token string) (*UserDTO, error) {
+	return s.ValidateToken(ctx, token)
+}
+func (s *cachingUserService) ValidateToken(ctx context.Context, token string) (*UserDTO, error) {
+	key := hashToken(token)
+	if entry, ok := s.get(key); ok {
+		if entry.err != nil {
+			s.negativeHits.Add(1)
+			return nil, entry.err
+		}
+		s.hits.Add(1)
+		user := *entry.user
+		return &user, nil
+	}
+	s.misses.Add(1)
+	user, err := s.next.ValidateToken(ctx, token)
+	switch {
+	case err == nil:
+		cached := *user
+		s.put(key, &cached, nil, s.positiveTTL(token))
+	case errors.Is(err, ErrInvalidToken) && s.negativeTTL > 0:
+		s.put(key, nil, err, s.negativeTTL)
+	}
+	return user, err
+}
+func (s *cachingUserService) CacheStats() TokenCacheStats {
+	s.mu.Lock()
+	size := s.lru.Len()
+	s.mu.Unlock()
+	stats := TokenCacheStats{
+		Hits:         s.hits.Load(),
+		NegativeHits: s.negativeHits.Load(),
+		Misses:       s.misses.Load(),
+		Evictions:    s.evictions.Load(),
+		Size:         size,
+	}
+	if total := stats.Hits + stats.NegativeHits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits+stats.NegativeHits) / float64(total)
+	}
+	return stats
+}
+func (s *cachingUserService) get(key string) (*tokenCacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*tokenCacheEntry)
+	if s.now().After(entry.expiresAt) {
+		s.lru.Remove(elem)
+		delete(s.entries, key)
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return entry, true
+}
+func (s *cachingUserService) put(key string, user *UserDTO, err error, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := &tokenCacheEntry{key: key, user: user, err: err, expiresAt: s.now().Add(ttl)}
+	if elem, ok := s.entries[key]; ok {
+		elem.Value = entry
+		s.lru.MoveToFront(elem)
+		return
+	}
+	s.entries[key] = s.lru.PushFront(entry)
+	for s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*tokenCacheEntry).key)
+		s.evictions.Add(1)
+	}
+}
+// positiveTTL is the cache TTL for a token that validated, cut short so the
+// entry never outlives the token's own exp claim. The token has just been
+// verified upstream, so reading the claim without checking the signature is
+// safe; tokens without a readable exp get the full TTL.
+func (s *cachingUserService) positiveTTL(token string) time.Duration {
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil || claims.ExpiresAt == nil {
+		return s.ttl
+	}
+	return min(s.ttl, claims.ExpiresAt.Sub(s.now()))
+}
+// hashToken keeps raw bearer tokens out of process memory held by the cache.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,185 @@
+package services
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"github.com/golang-jwt/jwt/v5"
+)
+// fakeClock is a manually advanced time source for TTL and cooldown tests.
+type fakeClock struct {
+	t time.Time
+}
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+// countingUserService answers from a fixed token table and counts the
+// validations that reach it.
+type countingUserService struct {
+	users map[string]*UserDTO
+	err   error
+	calls map[string]int
+}
+func (s *countingUserService) ValidateToken(ctx context.Context, token string) (*UserDTO, error) {
+	if s.calls == nil {
+		s.calls = make(map[string]int)
+	}
+	s.calls[token]++
+	if s.err != nil {
+		return nil, s.err
+	}
+	user, ok := s.users[token]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return user, nil
+}
+func (s *countingUserService) GetUserFromToken(ctx context.Context, token string) (*UserDTO, error) {
+	return s.ValidateToken(ctx, token)
+}
+func newTestTokenCache(next UserService, cfg TokenCacheConfig) (*cachingUserService, *fakeClock) {
+	clock := newFakeClock()
+	cache := NewCachingUserService(next, cfg).(*cachingUserService)
+	cache.now = clock.now
+	return cache, clock
+}
+func TestTokenCacheExpiresAfterTTL(t *testing.T) {
+	ctx := context.Background()
+	upstream := &countingUserService{users: map[string]*UserDTO{"a": {ID: 1}}}
+	cache, clock := newTestTokenCache(upstream, TokenCacheConfig{TTL: time.Minute})
+	for i := 0; i < 3; i++ {
+		user, err := cache.ValidateToken(ctx, "a")
+		if err != nil || user.ID != 1 {
+			t.Fatalf("ValidateToken = %v, %v, want user 1", user, err)
+		}
+	}
+	if upstream.calls["a"] != 1 {
+		t.Errorf("upstream calls = %d within TTL, want 1", upstream.calls["a"])
+	}
+	clock.advance(time.Minute + time.Second)
+	if _, err := cache.ValidateToken(ctx, "a"); err != nil {
+		t.Fatalf("ValidateToken after TTL: %v", err)
+	}
+	if upstream.calls["a"] != 2 {
+		t.Errorf("upstream calls = %d after TTL, want 2", upstream.calls["a"])
+	}
+	stats := cache.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 2 || stats.Size != 1 {
+		t.Errorf("stats = %+v, want 2 hits, 2 misses, size 1", stats)
+	}
+}
+func TestTokenCacheReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	upstream := &countingUserService{users: map[string]*UserDTO{"a": {ID: 1, Name: "original"}}}
+	cache, _ := newTestTokenCache(upstream, TokenCacheConfig{TTL: time.Minute})
+	user, _ := cache.ValidateToken(ctx, "a")
+	user.Name = "changed"
+	cached, _ := cache.ValidateToken(ctx, "a")
+	if cached.Name != "original" {
+		t.Errorf("cached name = %q, want the cache unaffected by callers", cached.Name)
+	}
+}
+func TestTokenCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	upstream := &countingUserService{users: map[string]*UserDTO{"a": {ID: 1}, "b": {ID: 2}, "c": {ID: 3}}}
+	cache, _ := newTestTokenCache(upstream, TokenCacheConfig{TTL: time.Minute, MaxEntries: 2})
+	for _, token := range []string{"a", "b", "a", "c"} {
+		if _, err := cache.ValidateToken(ctx, token); err != nil {
+			t.Fatalf("ValidateToken(%q): %v", token, err)
+		}
+	}
+	// "b" was the least recently used when "c" arrived.
+	for _, token := range []string{"a", "c", "b"} {
+		cache.ValidateToken(ctx, token)
+	}
+	want := map[string]int{"a": 1, "b": 2, "c": 1}
+	for token, calls := range want {
+		if upstream.calls[token] != calls {
+			t.Errorf("upstream calls for %q = %d, want %d", token, upstream.calls[token], calls)
+		}
+	}
+	stats := cache.CacheStats()
+	if stats.Size != 2 || stats.Evictions != 2 {
+		t.Errorf("stats = %+v, want size 2 and 2 evictions", stats)
+	}
+}
+func TestTokenCacheNegativeCaching(t *testing.T) {
+	ctx := context.Background()
+	upstream := &countingUserService{}
+	cache, clock := newTestTokenCache(upstream, TokenCacheConfig{TTL: time.Minute, NegativeTTL: 10 * time.Second})
+	for i := 0; i < 2; i++ {
+		if _, err := cache.ValidateToken(ctx, "bad"); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("ValidateToken = %v, want ErrInvalidToken", err)
+		}
+	}
+	if upstream.calls["bad"] != 1 {
+		t.Errorf("upstream calls = %d within negative TTL, want 1", upstream.calls["bad"])
+	}
+	if stats := cache.CacheStats(); stats.NegativeHits != 1 {
+		t.Errorf("negative hits = %d, want 1", stats.NegativeHits)
+	}
+	clock.advance(11 * time.Second)
+	cache.ValidateToken(ctx, "bad")
+	if upstream.calls["bad"] != 2 {
+		t.Errorf("upstream calls = %d after negative TTL, want 2", upstream.calls["bad"])
+	}
+}
+func TestTokenCacheSkipsNegativeCachingWhenDisabled(t *testing.T) {
+	ctx := context.Background()
+	upstream := &countingUserService{}
+	cache, _ := newTestTokenCache(upstream, TokenCacheConfig{TTL: time.Minute})
+	cache.ValidateToken(ctx, "bad")
+	cache.ValidateToken(ctx, "bad")
+	if upstream.calls["bad"] != 2 {
+		t.Errorf("upstream calls = %d, want every invalid token revalidated", upstream.calls["bad"])
+	}
+}
+func TestTokenCacheDoesNotCacheUpstreamErrors(t *testing.T) {
+	ctx := context.Background()
+	upstream := &countingUserService{err: ErrUpstreamUnavailable}
+	cache, _ := newTestTokenCache(upstream, TokenCacheConfig{TTL: time.Minute, NegativeTTL: time.Minute})
+	cache.ValidateToken(ctx, "a")
+	upstream.err = nil
+	upstream.users = map[string]*UserDTO{"a": {ID: 1}}
+	user, err := cache.ValidateToken(ctx, "a")
+	if err != nil || user.ID != 1 {
+		t.Errorf("ValidateToken = %v, %v, want user 1 once the upstream recovers", user, err)
+	}
+}
+func TestTokenCacheEntryDoesNotOutliveTokenExpiry(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	sign := func(exp time.Time) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(exp)}).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return token
+	}
+	soon := sign(clock.now().Add(10 * time.Second))
+	later := sign(clock.now().Add(time.Hour))
+	upstream := &countingUserService{users: map[string]*UserDTO{soon: {ID: 1}, later: {ID: 2}, "opaque": {ID: 3}}}
+	cache := NewCachingUserService(upstream, TokenCacheConfig{TTL: time.Minute}).(*cachingUserService)
+	cache.now = clock.now
+	for _, token := range []string{soon, later, "opaque"} {
+		if _, err := cache.ValidateToken(ctx, token); err != nil {
+			t.Fatalf("ValidateToken failed: %v", err)
+		}
+	}
+	clock.advance(11 * time.Second)
+	for _, token := range []string{soon, later, "opaque"} {
+		cache.ValidateToken(ctx, token)
+	}
+	if upstream.calls[soon] != 2 {
+		t.Errorf("upstream calls for a token past its exp = %d, want 2", upstream.calls[soon])
+	}
+	if upstream.calls[later] != 1 || upstream.calls["opaque"] != 1 {
+		t.Errorf("upstream calls = %d and %d, want tokens without an earlier exp served from the cache", upstream.calls[later], upstream.calls["opaque"])
+	}
+}
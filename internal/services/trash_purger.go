@@ -0,0 +1,51 @@
+package services
+import (
+	"context"
+	"log/slog"
+	"posts-api/internal/repository"
+	"time"
+)
+type TrashPurgerConfig struct {
+	// Retention is how long a post stays in the trash before it is purged.
+	Retention time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+// TrashPurger permanently deletes posts that have been in the trash longer
+// than the retention period. Run blocks until ctx is cancelled.
+type TrashPurger interface {
+	Run(ctx context.Context)
+	PurgeOnce(ctx context.Context) (int64, error)
+}
+type trashPurger struct {
+	postRepo repository.PostRepository
+	cfg      TrashPurgerConfig
+}
+func NewTrashPurger(postRepo repository.PostRepository, cfg TrashPurgerConfig) TrashPurger {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	return &trashPurger{postRepo: postRepo, cfg: cfg}
+}
+func (p *trashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if purged, err := p.PurgeOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Trash purge failed", "error", err, "purged", purged)
+		} else if purged > 0 {
+			slog.InfoContext(ctx, "Purged trashed posts", "purged", purged, "retention", p.cfg.Retention.String())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+func (p *trashPurger) PurgeOnce(ctx context.Context) (int64, error) {
+	return p.postRepo.PurgeDeletedPosts(ctx, time.Now().Add(-p.cfg.Retention), p.cfg.BatchSize)
+}
@@ -1,11 +1,20 @@
 package services
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"posts-api/internal/logging"
+	"posts-api/internal/metrics"
+	"posts-api/internal/tracing"
+	"strconv"
 	"time"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
 )
 type UserDTO struct {
 	ID    int64  `json:"id"`
@@ -20,58 +29,132 @@ type UsersAPIResponse struct {
 	Role   string `json:"role"`
 }
 type UserService interface {
-	ValidateToken(token string) (*UserDTO, error)
-	GetUserFromToken(token string) (*UserDTO, error) // Alias for ValidateToken for clarity
+	ValidateToken(ctx context.Context, token string) (*UserDTO, error)
+	GetUserFromToken(ctx context.Context, token string) (*UserDTO, error) // Alias for ValidateToken for clarity
+}
+type UserServiceConfig struct {
+	Timeout          time.Duration
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
 }
 type userService struct {
-	usersAPIURL string
-	httpClient  *http.Client
+	usersAPIURL  string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+	breaker      *circuitBreaker
 }
-func NewUserService(usersAPIURL string) UserService {
+func NewUserService(usersAPIURL string, cfg UserServiceConfig) UserService {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
 	return &userService{
 		usersAPIURL: usersAPIURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   cfg.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
 		},
+		maxRetries:   cfg.MaxRetries,
+		retryBackoff: cfg.RetryBackoff,
+		breaker:      newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+func (s *userService) GetUserFromToken(ctx context.Context, token string) (*UserDTO, error) {
+	return s.ValidateToken(ctx, token)
+}
+func (s *userService) ValidateToken(ctx context.Context, token string) (user *UserDTO, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ValidateToken")
+	defer func() { tracing.End(span, err) }()
+	if !s.breaker.allow() {
+		span.SetAttributes(attribute.Bool("users_api.breaker_open", true))
+		return nil, fmt.Errorf("%w: circuit breaker open", ErrUpstreamUnavailable)
 	}
+	user, err = s.validateWithRetry(ctx, token)
+	switch {
+	case ctx.Err() != nil:
+		s.breaker.release()
+	case errors.Is(err, ErrUpstreamUnavailable):
+		s.breaker.failure()
+	default:
+		s.breaker.success()
+	}
+	return user, err
+}
+// validateWithRetry retries /auth/me, which is idempotent, on transport
+// errors and retryable statuses with jittered exponential backoff.
+func (s *userService) validateWithRetry(ctx context.Context, token string) (*UserDTO, error) {
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(s.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+		user, err := s.fetchCurrentUser(ctx, token)
+		if err == nil || !errors.Is(err, ErrUpstreamUnavailable) || ctx.Err() != nil {
+			return user, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
 }
-func (s *userService) GetUserFromToken(token string) (*UserDTO, error) {
-	return s.ValidateToken(token)
+func (s *userService) backoff(attempt int) time.Duration {
+	dur := s.retryBackoff << (attempt - 1)
+	return dur/2 + rand.N(dur/2+1)
 }
-func (s *userService) ValidateToken(token string) (*UserDTO, error) {
+func (s *userService) fetchCurrentUser(ctx context.Context, token string) (*UserDTO, error) {
 	url := fmt.Sprintf("%s/auth/me", s.usersAPIURL)
 	
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
 	
+	start := time.Now()
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request to users API: %w", err)
+		metrics.ObserveUsersAPICall("auth_me", "error", time.Since(start))
+		return nil, fmt.Errorf("%w: failed to make request to users API: %v", ErrUpstreamUnavailable, err)
 	}
+	metrics.ObserveUsersAPICall("auth_me", strconv.Itoa(resp.StatusCode), time.Since(start))
 	defer resp.Body.Close()
 	
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("%w: failed to read response body: %v", ErrUpstreamUnavailable, err)
 	}
-	log.Printf("Users API Response - Status: %d, Body: %s", resp.StatusCode, string(body))
+	slog.DebugContext(ctx, "Users API responded", "status", resp.StatusCode, "duration_ms", time.Since(start).Milliseconds())
 	
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("invalid token - users API returned 401")
+		return nil, fmt.Errorf("%w - users API returned 401", ErrInvalidToken)
+	}
+	
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: users API returned status %d", ErrUpstreamUnavailable, resp.StatusCode)
 	}
 	
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("users API returned status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("users API returned status %d", resp.StatusCode)
 	}
 	
 	var usersAPIResp UsersAPIResponse
 	if err := json.Unmarshal(body, &usersAPIResp); err != nil {
-		return nil, fmt.Errorf("failed to decode users API response: %w, body: %s", err, string(body))
+		return nil, fmt.Errorf("failed to decode users API response: %w", err)
 	}
 	
 	userDTO := &UserDTO{
@@ -0,0 +1,41 @@
+// Package testutil holds fixtures shared by tests across packages.
+package testutil
+import (
+	"context"
+	"os"
+	"posts-api/internal/migrations"
+	"testing"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+// OpenDB connects to the database in TEST_DATABASE_URL, brings it to the
+// latest migration and empties every table. Tests that need Postgres are
+// skipped when the variable isn't set.
+func OpenDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database handle: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	migrator, err := migrations.NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := db.Exec("TRUNCATE posts, tags RESTART IDENTITY CASCADE").Error; err != nil {
+		t.Fatalf("failed to truncate tables: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,83 @@
+package tracing
+import (
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+const gormSpanKey = "tracing:span"
+// InstrumentGORM registers callbacks that wrap every statement GORM runs in a
+// client span, parented to the span carried by the statement context.
+func InstrumentGORM(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []func() error{
+		func() error {
+			return cb.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create"))
+		},
+		func() error { return cb.Create().After("gorm:create").Register("tracing:after_create", endSpan) },
+		func() error {
+			return cb.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query"))
+		},
+		func() error { return cb.Query().After("gorm:query").Register("tracing:after_query", endSpan) },
+		func() error {
+			return cb.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update"))
+		},
+		func() error { return cb.Update().After("gorm:update").Register("tracing:after_update", endSpan) },
+		func() error {
+			return cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete"))
+		},
+		func() error { return cb.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan) },
+		func() error { return cb.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")) },
+		func() error { return cb.Row().After("gorm:row").Register("tracing:after_row", endSpan) },
+		func() error { return cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")) },
+		func() error { return cb.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan) },
+	}
+	for _, register := range registrations {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func startSpan(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// Statements outside a traced request (migrations, health pings)
+			// would otherwise each start a new root trace.
+			return
+		}
+		_, span := Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation", operation),
+			),
+		)
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	table := db.Statement.Table
+	if table != "" {
+		span.SetAttributes(attribute.String("db.sql.table", table))
+	}
+	// The SQL text carries placeholders only; bound values are never recorded.
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
@@ -0,0 +1,41 @@
+package utils
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+type CursorDirection string
+const (
+	CursorNext CursorDirection = "next"
+	CursorPrev CursorDirection = "prev"
+)
+// Cursor points at a row in a listing ordered by (created_at, id) and is
+// handed to clients as an opaque base64 string.
+type Cursor struct {
+	CreatedAt time.Time       `json:"t"`
+	ID        int64           `json:"id"`
+	Direction CursorDirection `json:"d"`
+}
+var ErrInvalidCursor = errors.New("invalid cursor")
+func EncodeCursor(cursor Cursor) string {
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+func DecodeCursor(str string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID <= 0 || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Direction != CursorNext && cursor.Direction != CursorPrev {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,71 @@
+package utils
+import (
+	"reflect"
+	"testing"
+)
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffLine
+	}{
+		{
+			name: "both empty",
+			want: []DiffLine{},
+		},
+		{
+			name: "from empty",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffInsert, "one"}, {DiffInsert, "two"}},
+		},
+		{
+			name: "to empty",
+			a:    "one\ntwo",
+			want: []DiffLine{{DiffDelete, "one"}, {DiffDelete, "two"}},
+		},
+		{
+			name: "unchanged",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffEqual, "two"}},
+		},
+		{
+			name: "CRLF matches LF",
+			a:    "one\r\ntwo\r\n",
+			b:    "one\ntwo\n",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffEqual, "two"}, {DiffEqual, ""}},
+		},
+		{
+			name: "pure insert in the middle",
+			a:    "one\nthree",
+			b:    "one\ntwo\nthree",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffInsert, "two"}, {DiffEqual, "three"}},
+		},
+		{
+			name: "pure insert at the end",
+			a:    "one",
+			b:    "one\ntwo",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffInsert, "two"}},
+		},
+		{
+			name: "pure delete at the start",
+			a:    "one\ntwo\nthree",
+			b:    "two\nthree",
+			want: []DiffLine{{DiffDelete, "one"}, {DiffEqual, "two"}, {DiffEqual, "three"}},
+		},
+		{
+			name: "changed line is a delete then an insert",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			want: []DiffLine{{DiffEqual, "one"}, {DiffDelete, "two"}, {DiffInsert, "2"}, {DiffEqual, "three"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffLines(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffLines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+import (
+	"errors"
+	"net/http"
+)
+// ErrorKind classifies domain errors; its value is used as APIError.Code.
+type ErrorKind string
+const (
+	ErrorKindNotFound     ErrorKind = "NOT_FOUND"
+	ErrorKindForbidden    ErrorKind = "FORBIDDEN"
+	ErrorKindUnauthorized ErrorKind = "INVALID_TOKEN"
+	ErrorKindConflict     ErrorKind = "CONFLICT"
+	ErrorKindValidation   ErrorKind = "BUSINESS_VALIDATION_ERROR"
+	ErrorKindUpstream     ErrorKind = "UPSTREAM_UNAVAILABLE"
+	// ErrorKindPreconditionFailed is returned when an If-Match version no
+	// longer matches the stored resource.
+	ErrorKindPreconditionFailed ErrorKind = "PRECONDITION_FAILED"
+)
+// KindedError is implemented by errors that know which ErrorKind they are.
+type KindedError interface {
+	error
+	ErrorKind() ErrorKind
+}
+type errorKindResponse struct {
+	status  int
+	message string
+}
+var errorKindResponses = map[ErrorKind]errorKindResponse{
+	ErrorKindNotFound:           {http.StatusNotFound, "Resource not found"},
+	ErrorKindForbidden:          {http.StatusForbidden, "Access denied"},
+	ErrorKindUnauthorized:       {http.StatusUnauthorized, "Invalid token"},
+	ErrorKindConflict:           {http.StatusConflict, "Conflict"},
+	ErrorKindValidation:         {http.StatusBadRequest, "Validation failed"},
+	ErrorKindUpstream:           {http.StatusServiceUnavailable, "Upstream service unavailable"},
+	ErrorKindPreconditionFailed: {http.StatusPreconditionFailed, "Precondition failed"},
+}
+// WriteDomainErrorResponse maps an error returned by the service layer to a
+// status code and error code. Errors without a kind are treated as internal.
+// Details only carry the kinded error's own message, not whatever wrapped it.
+func WriteDomainErrorResponse(w http.ResponseWriter, err error) {
+	var kinded KindedError
+	if !errors.As(err, &kinded) {
+		WriteInternalErrorResponse(w, err)
+		return
+	}
+	resp, ok := errorKindResponses[kinded.ErrorKind()]
+	if !ok {
+		WriteInternalErrorResponse(w, err)
+		return
+	}
+	details := kinded.Error()
+	if VerboseErrors() {
+		details = err.Error()
+	}
+	WriteErrorResponse(w, resp.status, resp.message, string(kinded.ErrorKind()), details)
+}
@@ -0,0 +1,46 @@
+package utils
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+var ErrInvalidETag = errors.New("invalid entity tag")
+// FormatETag renders a resource version as a strong entity tag. Details that
+// change without a new version, such as a post's comment count, follow it
+// after a hyphen so cached representations are still revalidated.
+func FormatETag(version int64, details ...int64) string {
+	etag := strconv.FormatInt(version, 10)
+	for _, detail := range details {
+		etag += "-" + strconv.FormatInt(detail, 10)
+	}
+	return `"` + etag + `"`
+}
+// ParseIfMatch reads an If-Match header holding a single strong entity tag
+// produced by FormatETag, or "*". wildcard is true for "*". Only the version
+// is returned: writes are conditional on the version alone.
+func ParseIfMatch(header string) (version int64, wildcard bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, true, nil
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false, ErrInvalidETag
+	}
+	tag, _, _ := strings.Cut(header[1:len(header)-1], "-")
+	version, err = strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return 0, false, ErrInvalidETag
+	}
+	return version, false, nil
+}
+// ETagMatchesNoneMatch reports whether an If-None-Match header matches etag,
+// using the weak comparison the header calls for.
+func ETagMatchesNoneMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package utils
+import (
+	"errors"
+	"testing"
+)
+func TestFormatETag(t *testing.T) {
+	if got := FormatETag(3); got != `"3"` {
+		t.Errorf("FormatETag(3) = %s, want \"3\"", got)
+	}
+	if got := FormatETag(3, 5); got != `"3-5"` {
+		t.Errorf("FormatETag(3, 5) = %s, want \"3-5\"", got)
+	}
+	if FormatETag(3, 5) == FormatETag(3, 6) {
+		t.Error("ETags for different comment counts must differ")
+	}
+}
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header       string
+		wantVersion  int64
+		wantWildcard bool
+		wantErr      error
+	}{
+		{header: `"3"`, wantVersion: 3},
+		{header: `"3-5"`, wantVersion: 3},
+		{header: ` "12-0" `, wantVersion: 12},
+		{header: `*`, wantWildcard: true},
+		{header: `3`, wantErr: ErrInvalidETag},
+		{header: `W/"3"`, wantErr: ErrInvalidETag},
+		{header: `"x-5"`, wantErr: ErrInvalidETag},
+		{header: `"3", "4"`, wantErr: ErrInvalidETag},
+	}
+	for _, tt := range tests {
+		version, wildcard, err := ParseIfMatch(tt.header)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseIfMatch(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			continue
+		}
+		if version != tt.wantVersion || wildcard != tt.wantWildcard {
+			t.Errorf("ParseIfMatch(%q) = %d, %v, want %d, %v", tt.header, version, wildcard, tt.wantVersion, tt.wantWildcard)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package utils
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+var (
+	ErrInvalidPatch    = errors.New("invalid patch document")
+	ErrPatchTestFailed = errors.New("patch test operation failed")
+)
+// ApplyMergePatch applies an RFC 7396 JSON Merge Patch to doc.
+func ApplyMergePatch(doc map[string]interface{}, patch []byte) (map[string]interface{}, error) {
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	patchObj, ok := p.(map[string]interface{})
+	if !ok {
+		// A non-object patch replaces the whole document, which is never a
+		// valid post.
+		return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidPatch)
+	}
+	return mergePatch(doc, patchObj), nil
+}
+// mergePatch returns a copy of target with patch merged in, leaving target
+// and any objects nested in it untouched.
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(target))
+	for key, value := range target {
+		result[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		if patchObj, ok := value.(map[string]interface{}); ok {
+			targetObj, _ := result[key].(map[string]interface{})
+			result[key] = mergePatch(targetObj, patchObj)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+type jsonPatchOp struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	// Value stays empty when the member is absent and holds "null" for an
+	// explicit JSON null, which is a valid value to test or replace with.
+	Value json.RawMessage `json:"value"`
+}
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to doc. Only the test,
+// replace and remove operations on top-level members are supported, which
+// covers every field of a post. Operations apply atomically: doc is not
+// modified when any operation fails.
+func ApplyJSONPatch(doc map[string]interface{}, patch []byte) (map[string]interface{}, error) {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	result := make(map[string]interface{}, len(doc))
+	for key, value := range doc {
+		result[key] = value
+	}
+	for i, op := range ops {
+		key, err := topLevelMember(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+		}
+		current, exists := result[key]
+		switch op.Op {
+		case "test":
+			value, err := opValue(op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+			}
+			if !exists || !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: operation %d: %s does not match", ErrPatchTestFailed, i, op.Path)
+			}
+		case "replace":
+			if !exists {
+				return nil, fmt.Errorf("%w: operation %d: %s does not exist", ErrInvalidPatch, i, op.Path)
+			}
+			value, err := opValue(op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+			}
+			result[key] = value
+		case "remove":
+			if !exists {
+				return nil, fmt.Errorf("%w: operation %d: %s does not exist", ErrInvalidPatch, i, op.Path)
+			}
+			delete(result, key)
+		default:
+			return nil, fmt.Errorf("%w: operation %d: unsupported op %q", ErrInvalidPatch, i, op.Op)
+		}
+	}
+	return result, nil
+}
+// topLevelMember decodes a JSON Pointer that names a direct member of the
+// document, such as "/title".
+func topLevelMember(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") || strings.Count(pointer, "/") != 1 {
+		return "", fmt.Errorf("path %q must name a top-level member", pointer)
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:]), nil
+}
+func opValue(op jsonPatchOp) (interface{}, error) {
+	if len(op.Value) == 0 {
+		return nil, fmt.Errorf("%s operation on %s requires a value", op.Op, op.Path)
+	}
+	var value interface{}
+	if err := json.Unmarshal(op.Value, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
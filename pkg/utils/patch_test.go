@@ -0,0 +1,236 @@
+package utils
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name:  "replaces a member",
+			doc:   `{"title":"Old","content":"Body"}`,
+			patch: `{"title":"New"}`,
+			want:  map[string]interface{}{"title": "New", "content": "Body"},
+		},
+		{
+			name:  "null removes a member",
+			doc:   `{"title":"Old","content":"Body"}`,
+			patch: `{"content":null}`,
+			want:  map[string]interface{}{"title": "Old"},
+		},
+		{
+			name:  "null removes a nested member",
+			doc:   `{"meta":{"a":"1","b":"2"}}`,
+			patch: `{"meta":{"a":null}}`,
+			want:  map[string]interface{}{"meta": map[string]interface{}{"b": "2"}},
+		},
+		{
+			name:  "null on a missing member is a no-op",
+			doc:   `{"title":"Old"}`,
+			patch: `{"missing":null}`,
+			want:  map[string]interface{}{"title": "Old"},
+		},
+		{
+			name:  "arrays are replaced whole",
+			doc:   `{"tags":["a","b"]}`,
+			patch: `{"tags":["c"]}`,
+			want:  map[string]interface{}{"tags": []interface{}{"c"}},
+		},
+		{
+			name:    "non-object patch is rejected",
+			doc:     `{"title":"Old"}`,
+			patch:   `["title"]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "malformed JSON is rejected",
+			doc:     `{"title":"Old"}`,
+			patch:   `{"title":`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := decodeDoc(t, tt.doc)
+			got, err := ApplyMergePatch(doc, []byte(tt.patch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("result = %#v, want %#v", got, tt.want)
+			}
+			if want := decodeDoc(t, tt.doc); !reflect.DeepEqual(doc, want) {
+				t.Errorf("document = %#v, want it unchanged", doc)
+			}
+		})
+	}
+}
+func TestApplyJSONPatch(t *testing.T) {
+	doc := func() map[string]interface{} {
+		return map[string]interface{}{
+			"title":      "Old",
+			"content":    "Body",
+			"tags":       []interface{}{"go"},
+			"publish_at": nil,
+			"a/b":        "slash",
+			"m~n":        "tilde",
+		}
+	}
+	with := func(changes map[string]interface{}, removed ...string) map[string]interface{} {
+		d := doc()
+		for key, value := range changes {
+			d[key] = value
+		}
+		for _, key := range removed {
+			delete(d, key)
+		}
+		return d
+	}
+	tests := []struct {
+		name    string
+		patch   string
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/title","value":"New"}]`,
+			want:  with(map[string]interface{}{"title": "New"}),
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/tags"}]`,
+			want:  with(nil, "tags"),
+		},
+		{
+			name:  "passing test then replace",
+			patch: `[{"op":"test","path":"/tags","value":["go"]},{"op":"replace","path":"/tags","value":[]}]`,
+			want:  with(map[string]interface{}{"tags": []interface{}{}}),
+		},
+		{
+			name:  "test against null",
+			patch: `[{"op":"test","path":"/publish_at","value":null}]`,
+			want:  doc(),
+		},
+		{
+			name:  "replace with null",
+			patch: `[{"op":"replace","path":"/title","value":null}]`,
+			want:  with(map[string]interface{}{"title": nil}),
+		},
+		{
+			name:  "escaped slash in pointer",
+			patch: `[{"op":"replace","path":"/a~1b","value":"x"}]`,
+			want:  with(map[string]interface{}{"a/b": "x"}),
+		},
+		{
+			name:  "escaped tilde in pointer",
+			patch: `[{"op":"test","path":"/m~0n","value":"tilde"},{"op":"remove","path":"/m~0n"}]`,
+			want:  with(nil, "m~n"),
+		},
+		{
+			name:    "failing test",
+			patch:   `[{"op":"test","path":"/title","value":"Other"}]`,
+			wantErr: ErrPatchTestFailed,
+		},
+		{
+			name:    "test of a missing member",
+			patch:   `[{"op":"test","path":"/missing","value":null}]`,
+			wantErr: ErrPatchTestFailed,
+		},
+		{
+			name:    "test without a value",
+			patch:   `[{"op":"test","path":"/title"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "replace without a value",
+			patch:   `[{"op":"replace","path":"/title"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "replace of a missing member",
+			patch:   `[{"op":"replace","path":"/missing","value":"x"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "remove of a missing member",
+			patch:   `[{"op":"remove","path":"/missing"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "nested path",
+			patch:   `[{"op":"replace","path":"/tags/0","value":"x"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "unsupported op",
+			patch:   `[{"op":"add","path":"/title","value":"x"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+		{
+			name:    "not an array",
+			patch:   `{"op":"replace","path":"/title","value":"x"}`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyJSONPatch(doc(), []byte(tt.patch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("result = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+func TestApplyJSONPatchIsAtomic(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr error
+	}{
+		{
+			name:    "failing test after a replace",
+			patch:   `[{"op":"replace","path":"/title","value":"New"},{"op":"test","path":"/content","value":"Other"}]`,
+			wantErr: ErrPatchTestFailed,
+		},
+		{
+			name:    "invalid op after a remove",
+			patch:   `[{"op":"remove","path":"/content"},{"op":"move","path":"/title"}]`,
+			wantErr: ErrInvalidPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := map[string]interface{}{"title": "Old", "content": "Body"}
+			got, err := ApplyJSONPatch(doc, []byte(tt.patch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != nil {
+				t.Errorf("result = %#v, want nil", got)
+			}
+			want := map[string]interface{}{"title": "Old", "content": "Body"}
+			if !reflect.DeepEqual(doc, want) {
+				t.Errorf("document = %#v, want it unchanged", doc)
+			}
+		})
+	}
+}
+func decodeDoc(t *testing.T, doc string) map[string]interface{} {
+	t.Helper()
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &decoded); err != nil {
+		t.Fatalf("invalid test document: %v", err)
+	}
+	return decoded
+}
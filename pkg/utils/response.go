@@ -1,7 +1,11 @@
 package utils
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"log/slog"
 	"net/http"
+	"sync/atomic"
 )
 type APIResponse struct {
 	Success bool        `json:"success"`
@@ -13,6 +17,7 @@ type APIError struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	ErrorID string `json:"error_id,omitempty"`
 }
 type ValidationError struct {
 	Field   string `json:"field"`
@@ -64,9 +69,41 @@ func WriteNotFoundResponse(w http.ResponseWriter, resource string) {
 		"NOT_FOUND",
 		"The requested "+resource+" does not exist")
 }
+var verboseErrors atomic.Bool
+// SetVerboseErrors controls whether error responses include internal error
+// text. It should only be enabled in development.
+func SetVerboseErrors(enabled bool) {
+	verboseErrors.Store(enabled)
+}
+func VerboseErrors() bool {
+	return verboseErrors.Load()
+}
+// WriteInternalErrorResponse logs err under a fresh error ID and returns only
+// that ID to the client, unless verbose errors are enabled.
 func WriteInternalErrorResponse(w http.ResponseWriter, err error) {
-	WriteErrorResponse(w, http.StatusInternalServerError,
-		"Internal server error",
-		"INTERNAL_ERROR",
-		err.Error())
+	errorID := newErrorID()
+	slog.Error("Internal error",
+		"error_id", errorID,
+		"request_id", w.Header().Get("X-Request-ID"),
+		"error", err)
+	details := "Use the error_id when reporting this problem"
+	if VerboseErrors() {
+		details = err.Error()
+	}
+	response := APIResponse{
+		Success: false,
+		Message: "Internal server error",
+		Error: &APIError{
+			Code:    "INTERNAL_ERROR",
+			Message: "Internal server error",
+			Details: details,
+			ErrorID: errorID,
+		},
+	}
+	WriteJSONResponse(w, http.StatusInternalServerError, response)
+}
+func newErrorID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,48 @@
+package utils
+import (
+	"strings"
+	"unicode"
+	"golang.org/x/text/unicode/norm"
+)
+// foldedLetters spells out Latin letters that don't decompose into an ASCII
+// letter plus accents.
+var foldedLetters = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'đ': "d",
+	'ð': "d",
+	'þ': "th",
+	'ł': "l",
+	'ı': "i",
+}
+// Slugify lowercases s and reduces it to ASCII letters and digits separated
+// by single hyphens, so "Go Tips!" and "go_tips" both become "go-tips".
+// Accented Latin letters are folded to their base letter ("Café" becomes
+// "cafe"); other scripts have no ASCII spelling and are dropped like
+// punctuation, so a name written only in them, such as "日本", yields "".
+func Slugify(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	write := func(letters string) {
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(letters)
+	}
+	for _, r := range norm.NFKD.String(strings.ToLower(s)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			write(string(r))
+		case unicode.Is(unicode.Mn, r):
+			// Accents split off by the decomposition above.
+		case foldedLetters[r] != "":
+			write(foldedLetters[r])
+		default:
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package utils
+import "testing"
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Go Tips!", "go-tips"},
+		{"go_tips", "go-tips"},
+		{"  --Go--  ", "go"},
+		{"PostgreSQL 16", "postgresql-16"},
+		{"café", "cafe"},
+		{"Crème Brûlée", "creme-brulee"},
+		{"naïve résumé", "naive-resume"},
+		{"Straße", "strasse"},
+		{"Łódź", "lodz"},
+		{"Ærø", "aero"},
+		{"ﬁle", "file"},
+		{"Go 日本", "go"},
+		{"日本", ""},
+		{"!!!", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Slugify(tt.in); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}